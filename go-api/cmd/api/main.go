@@ -5,18 +5,27 @@ import (
 	"context"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/joho/godotenv"
 	_ "github.com/kavishankarks/itp-rag-processor/go-api/docs"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/database"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/embedding_client"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/handlers"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/llm"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/pipeline"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/utils/hal"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/vector"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"gorm.io/gorm"
 )
 
 // @title Document Hub API
@@ -35,6 +44,14 @@ import (
 // @BasePath /api/v1
 // @schemes http https
 
+// jobLeaseJanitorInterval is how often both lease-expiry janitors (durable
+// job queue and in-memory run) sweep for dead workers; jobLeaseTTL is how
+// stale a durable job's lease can get before it's reclaimed.
+const (
+	jobLeaseJanitorInterval = 30 * time.Second
+	jobLeaseTTL             = 3 * time.Minute
+)
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
@@ -43,10 +60,12 @@ func main() {
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
-		AppName: os.Getenv("APP_NAME"),
+		AppName:      os.Getenv("APP_NAME"),
+		ErrorHandler: errs.Middleware,
 	})
 
 	// Middleware
+	app.Use(requestid.New())
 	app.Use(logger.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
@@ -68,56 +87,198 @@ func main() {
 		})
 	})
 
-	// Initialize Milvus client
-	milvusURL := os.Getenv("MILVUS_URL")
-	milvusToken := os.Getenv("MILVUS_TOKEN")
-	if milvusURL == "" || milvusToken == "" {
-		log.Fatal("MILVUS_URL and MILVUS_TOKEN environment variables are required")
-	}
+	// Initialize the vector store backend. Defaults to Milvus for backwards
+	// compatibility; set VECTOR_BACKEND=pgvector to run against Postgres
+	// alone, without Milvus credentials.
+	var store vector.Store
+	var pgDB *gorm.DB
+	backend := os.Getenv("VECTOR_BACKEND")
 
-	milvusClient, err := vector.Initialize(milvusURL, milvusToken)
-	if err != nil {
-		log.Fatal("Failed to initialize Milvus client:", err)
+	switch backend {
+	case "pgvector":
+		db, err := database.Initialize()
+		if err != nil {
+			log.Fatal("Failed to initialize database:", err)
+		}
+
+		pgDB = db
+		store = vector.NewPGVectorStore(db)
+	default:
+		milvusURL := os.Getenv("MILVUS_URL")
+		milvusToken := os.Getenv("MILVUS_TOKEN")
+		if milvusURL == "" || milvusToken == "" {
+			log.Fatal("MILVUS_URL and MILVUS_TOKEN environment variables are required")
+		}
+
+		milvusClient, err := vector.Initialize(milvusURL, milvusToken)
+		if err != nil {
+			log.Fatal("Failed to initialize Milvus client:", err)
+		}
+		defer milvusClient.Close()
+
+		store = milvusClient
 	}
-	defer milvusClient.Close()
 
-	if err := milvusClient.EnsureCollections(); err != nil {
-		log.Fatal("Failed to ensure Milvus collection:", err)
+	if err := store.EnsureCollections(); err != nil {
+		log.Fatal("Failed to ensure vector store collections:", err)
 	}
 
 	// Initialize handlers
-	h := handlers.NewHandler(milvusClient)
+	h, err := handlers.NewHandler(store)
+	if err != nil {
+		log.Fatal("Failed to initialize handlers:", err)
+	}
 
-	// Initialize embedding client and pipeline orchestrator
+	// Initialize embedding client and pipeline orchestrator. SEARCH_ENGINE
+	// picks (or, comma-separated, fans out to) Go-side web search providers
+	// for the enrichment stage; leaving it unset keeps the previous
+	// behavior of delegating search to the embedding microservice.
 	embeddingClient := embedding_client.NewClient()
-	orchestrator := pipeline.NewOrchestrator(embeddingClient, milvusClient)
-	pipelineHandler := handlers.NewPipelineHandler(orchestrator)
-
-	// Initialize LLM provider
-	var llmProvider llm.LLMProvider
-	llmProviderType := os.Getenv("LLM_PROVIDER")
+	searchProvider, err := pipeline.NewSearchProviderFromEnv()
+	if err != nil {
+		log.Fatal("Failed to initialize search provider:", err)
+	}
+	orchestrator := pipeline.NewOrchestrator(embeddingClient, store, searchProvider)
 
-	if llmProviderType == "groq" {
-		llmProvider, err = llm.NewGroqProvider()
+	// Durable pipeline execution needs Postgres regardless of which vector
+	// backend was chosen above. Reuse the pgvector connection if we already
+	// have one; otherwise open a dedicated one if DATABASE_URL is set. If
+	// neither applies, the orchestrator falls back to its in-memory
+	// goroutine so Milvus-only deployments without Postgres still work.
+	jobQueueDB := pgDB
+	if jobQueueDB == nil && os.Getenv("DATABASE_URL") != "" {
+		db, err := database.Initialize()
 		if err != nil {
-			log.Printf("Warning: Failed to initialize Groq provider: %v", err)
+			log.Printf("Warning: Failed to initialize pipeline job queue database: %v", err)
+		} else {
+			jobQueueDB = db
+		}
+	}
+
+	if jobQueueDB != nil {
+		jobQueue := pipeline.NewJobQueue(jobQueueDB)
+		orchestrator.SetJobQueue(jobQueue, jobQueueDB)
+
+		if n, err := jobQueue.RecoverStuckJobs(); err != nil {
+			log.Printf("Warning: failed to recover stuck pipeline jobs: %v", err)
+		} else if n > 0 {
+			log.Printf("Recovered %d stuck pipeline job(s)", n)
+		}
+
+		if err := orchestrator.RecoverIncompleteRuns(); err != nil {
+			log.Printf("Warning: failed to recover incomplete pipeline runs: %v", err)
 		}
-	} else {
-		// Default to Gemini
-		llmProvider, err = llm.NewGeminiProvider(context.Background())
+
+		workerCount := 4
+		if wc := os.Getenv("PIPELINE_WORKERS"); wc != "" {
+			if parsed, err := strconv.Atoi(wc); err == nil && parsed > 0 {
+				workerCount = parsed
+			}
+		}
+
+		workerPool := pipeline.NewWorkerPool(orchestrator, jobQueue, workerCount)
+		workerPool.Start(context.Background())
+
+		// Periodically reclaim jobs whose worker crashed mid-processing,
+		// beyond the one-time sweep above, so a later crash doesn't need a
+		// full API restart to recover from.
+		go func() {
+			ticker := time.NewTicker(jobLeaseJanitorInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if n, err := jobQueue.RecoverExpiredLeases(jobLeaseTTL); err != nil {
+					log.Printf("Warning: failed to recover expired pipeline job leases: %v", err)
+				} else if n > 0 {
+					log.Printf("Recovered %d pipeline job(s) with an expired lease", n)
+				}
+			}
+		}()
+	}
+
+	// STATE_STORE optionally shares pipeline run state across API replicas
+	// (see Orchestrator.SetStateStore): "postgres" reuses jobQueueDB,
+	// "etcd" needs ETCD_ENDPOINTS. Leaving it unset keeps runs local to
+	// whichever process started them, same as before.
+	switch os.Getenv("STATE_STORE") {
+	case "postgres":
+		if jobQueueDB == nil {
+			log.Printf("Warning: STATE_STORE=postgres requires a Postgres connection; pipeline state store not enabled")
+		} else {
+			orchestrator.SetStateStore(pipeline.NewPostgresStateStore(jobQueueDB))
+		}
+	case "etcd":
+		endpoints := strings.Split(os.Getenv("ETCD_ENDPOINTS"), ",")
+		etcdClient, err := clientv3.New(clientv3.Config{Endpoints: endpoints, DialTimeout: 5 * time.Second})
 		if err != nil {
-			log.Printf("Warning: Failed to initialize Gemini provider: %v", err)
+			log.Printf("Warning: failed to connect to etcd for pipeline state store: %v", err)
+		} else {
+			prefix := os.Getenv("ETCD_PREFIX")
+			if prefix == "" {
+				prefix = "/itp-rag-processor"
+			}
+			orchestrator.SetStateStore(pipeline.NewEtcdStateStore(etcdClient, prefix))
 		}
 	}
 
+	// Detects an in-memory pipeline run whose goroutine died without going
+	// through its normal failure path (see Orchestrator.StartLeaseJanitor).
+	orchestrator.StartLeaseJanitor(jobLeaseJanitorInterval, jobLeaseTTL)
+
+	pipelineHandler := handlers.NewPipelineHandler(orchestrator)
+
+	// Initialize LLM provider. LLM_PROVIDER selects the backend by name from
+	// llm.DefaultRegistry (gemini, groq, openai, anthropic, ollama); model,
+	// temperature, max tokens, and safety threshold are config-driven via
+	// LLM_MODEL/LLM_TEMPERATURE/LLM_MAX_TOKENS/LLM_SAFETY_THRESHOLD instead
+	// of being hardcoded per provider.
+	llmProviderName := os.Getenv("LLM_PROVIDER")
+	if llmProviderName == "" {
+		llmProviderName = "gemini"
+	}
+	llmConfig := llm.Config{Model: os.Getenv("LLM_MODEL"), SafetyThreshold: os.Getenv("LLM_SAFETY_THRESHOLD")}
+	if v := os.Getenv("LLM_TEMPERATURE"); v != "" {
+		if temp, err := strconv.ParseFloat(v, 64); err == nil {
+			llmConfig.Temperature = temp
+		}
+	}
+	if v := os.Getenv("LLM_MAX_TOKENS"); v != "" {
+		if maxTokens, err := strconv.Atoi(v); err == nil {
+			llmConfig.MaxTokens = maxTokens
+		}
+	}
+
+	llmProvider, err := llm.DefaultRegistry.New(context.Background(), llmProviderName, llmConfig)
+	if err != nil {
+		log.Printf("Warning: Failed to initialize %s LLM provider: %v", llmProviderName, err)
+	}
+
 	generateHandler := handlers.NewGenerateHandler(llmProvider, h)
 
+	// API root: advertises the top-level link relations so clients can
+	// discover documents, search, and pipelines via HATEOAS instead of
+	// hardcoding URLs.
+	app.Get("/api", func(c *fiber.Ctx) error {
+		return hal.SendHAL(c, fiber.StatusOK, fiber.Map{}, hal.Links{
+			"self":            {Href: "/api"},
+			"documents":       {Href: "/api/v1/documents"},
+			"document_upload": {Href: "/api/v1/documents/upload", Method: "POST"},
+			"search":          {Href: "/api/v1/search"},
+			"generate":        {Href: "/api/v1/generate", Method: "POST"},
+			"generate_stream": {Href: "/api/v1/generate/stream", Method: "POST"},
+			"pipelines":       {Href: "/api/v1/pipelines"},
+			"pipeline_start":  {Href: "/api/v1/pipeline/start", Method: "POST"},
+		})
+	})
+
 	// API routes
 	api := app.Group("/api/v1")
 
 	// Document routes (existing functionality)
 	api.Post("/documents", h.CreateDocument)
+	api.Post("/documents/_bulk", h.BulkCreateDocuments)
 	api.Post("/documents/upload", h.UploadDocument)
+	api.Get("/documents/jobs/:id", h.GetUploadJobStatus)
+	api.Post("/documents/upsert", h.UpsertDocument)
 	api.Get("/documents/:id", h.GetDocument)
 	api.Get("/documents", h.ListDocuments)
 	api.Put("/documents/:id", h.UpdateDocument)
@@ -126,14 +287,23 @@ func main() {
 	// Search routes (existing functionality)
 	api.Get("/search", h.Search)
 
+	// Tenant partition management (vector.Store multi-tenancy)
+	api.Put("/tenants/:id", h.CreateTenant)
+	api.Delete("/tenants/:id", h.DeleteTenant)
+
 	// Generation routes (new)
 	api.Post("/generate", generateHandler.Generate)
+	api.Post("/generate/stream", generateHandler.GenerateStream)
 
 	// Pipeline routes (new RAG processing pipeline)
 	api.Post("/pipeline/start", pipelineHandler.StartPipeline)
 	api.Get("/pipeline/:id/status", pipelineHandler.GetPipelineStatus)
+	api.Get("/pipeline/:id/events", pipelineHandler.StreamEvents)
+	api.Get("/pipeline/:id/logs", pipelineHandler.StreamLogs)
 	api.Get("/pipeline/:id/results", pipelineHandler.GetPipelineResults)
 	api.Post("/pipeline/:id/cancel", pipelineHandler.CancelPipeline)
+	api.Post("/pipeline/:id/resume", pipelineHandler.ResumePipeline)
+	api.Post("/pipeline/:id/retry", pipelineHandler.RetryFailedTopics)
 	api.Get("/pipelines", pipelineHandler.ListPipelines)
 
 	// Start server
@@ -0,0 +1,91 @@
+// Package chunking splits document text into retrieval-sized pieces using a
+// pluggable set of strategies (fixed recursive-character splitting, Markdown
+// heading-aware splitting, embedding-based semantic splitting, tokenizer-
+// aware splitting, and "late chunking" over whole-document token
+// embeddings). Callers pick a strategy by name through Get and keep the same
+// Chunker/Options/Chunk shapes regardless of which one is selected.
+package chunking
+
+// DefaultStrategy is used when a caller doesn't specify one.
+const DefaultStrategy = "recursive"
+
+// DefaultMaxSize is the fallback target chunk size, in characters, used by
+// any chunker that isn't given an explicit Options.MaxSize.
+const DefaultMaxSize = 500
+
+// Chunk is a single piece of text produced by a Chunker, along with enough
+// context for retrieval to cite back to the source document.
+type Chunk struct {
+	Text  string
+	Start int // byte offset of Text within the chunked input
+	End   int // byte offset, exclusive
+
+	// HeadingPath is the stack of Markdown H1->H3 headings in effect where
+	// this chunk starts, outermost first (e.g. ["Setup", "Installation"]).
+	// Populated by MarkdownHeadingChunker; empty for strategies that don't
+	// track headings.
+	HeadingPath []string
+
+	// Embedding is the chunk's vector, populated only by LateChunker via
+	// mean-pooling over the whole document's token embeddings. Every other
+	// strategy leaves this nil; callers embed Chunk.Text themselves in that
+	// case, same as before LateChunker existed.
+	Embedding []float32
+}
+
+// Options carries the knobs shared across chunking strategies. Not every
+// field applies to every Chunker (e.g. Embedder is only used by
+// SemanticChunker); unused fields are ignored. MaxSize and Overlap count
+// characters for every strategy except TokenChunker, which counts tokens.
+type Options struct {
+	MaxSize       int           // target max chunk size; DefaultMaxSize if <= 0
+	Overlap       int           // overlap between adjacent chunks
+	Embedder      Embedder      // required by SemanticChunker
+	TokenEmbedder TokenEmbedder // required by TokenChunker and LateChunker
+}
+
+// Embedder is the subset of embedding_client.EmbeddingClient that
+// SemanticChunker needs, kept as a local interface so this package doesn't
+// import the HTTP client.
+type Embedder interface {
+	GetEmbeddings(texts []string) ([][]float32, error)
+}
+
+// TokenEmbedder is the subset of embedding_client.EmbeddingClient that
+// TokenChunker and LateChunker need: a single call that tokenizes text with
+// the embedding model's own tokenizer and returns one contextual embedding
+// per token, alongside each token's byte offset into text. Kept as a local
+// interface so this package doesn't import the HTTP client.
+type TokenEmbedder interface {
+	GetTokenEmbeddings(text string) (offsets [][2]int, embeddings [][]float32, err error)
+}
+
+// Chunker splits text into Chunks according to its own strategy and opts.
+type Chunker interface {
+	Chunk(text string, opts Options) ([]Chunk, error)
+}
+
+var registry = map[string]Chunker{
+	"recursive":        RecursiveCharacterChunker{},
+	"markdown_heading": MarkdownHeadingChunker{},
+	"semantic":         SemanticChunker{},
+	"token":            TokenChunker{},
+	"late_chunking":    LateChunker{},
+}
+
+// Get returns the registered Chunker for name, or false if it's unknown.
+func Get(name string) (Chunker, bool) {
+	if name == "" {
+		name = DefaultStrategy
+	}
+	c, ok := registry[name]
+	return c, ok
+}
+
+// maxSizeOrDefault returns opts.MaxSize if set, otherwise DefaultMaxSize.
+func maxSizeOrDefault(opts Options) int {
+	if opts.MaxSize > 0 {
+		return opts.MaxSize
+	}
+	return DefaultMaxSize
+}
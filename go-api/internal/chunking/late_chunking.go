@@ -0,0 +1,67 @@
+package chunking
+
+import "fmt"
+
+// LateChunker implements "late chunking": instead of embedding each chunk's
+// text in isolation, the whole document is tokenized and embedded once via
+// the embedding microservice's token-level endpoint, and each chunk's vector
+// is the mean of its member tokens' contextual embeddings. Because those
+// token embeddings already carry context from the rest of the document,
+// chunks near a boundary keep more of the meaning a naive per-chunk
+// embedding call would otherwise lose. Chunk boundaries themselves come from
+// RecursiveCharacterChunker; only the embedding step differs.
+//
+// Callers using LateChunker should use each Chunk's Embedding field directly
+// instead of re-embedding Chunk.Text.
+type LateChunker struct{}
+
+func (LateChunker) Chunk(text string, opts Options) ([]Chunk, error) {
+	if opts.TokenEmbedder == nil {
+		return nil, fmt.Errorf("late chunking requires a TokenEmbedder")
+	}
+
+	chunks, err := (RecursiveCharacterChunker{}).Chunk(text, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets, embeddings, err := opts.TokenEmbedder.GetTokenEmbeddings(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token embeddings: %w", err)
+	}
+
+	for i := range chunks {
+		chunks[i].Embedding = poolTokenEmbeddings(offsets, embeddings, chunks[i].Start, chunks[i].End)
+	}
+
+	return chunks, nil
+}
+
+// poolTokenEmbeddings mean-pools every token embedding whose offset overlaps
+// [start, end), producing one vector per chunk out of the single
+// whole-document embedding call.
+func poolTokenEmbeddings(offsets [][2]int, embeddings [][]float32, start, end int) []float32 {
+	var sum []float32
+	var count int
+
+	for i, off := range offsets {
+		if off[1] <= start || off[0] >= end {
+			continue
+		}
+		if sum == nil {
+			sum = make([]float32, len(embeddings[i]))
+		}
+		for d, v := range embeddings[i] {
+			sum[d] += v
+		}
+		count++
+	}
+
+	if count == 0 {
+		return nil
+	}
+	for d := range sum {
+		sum[d] /= float32(count)
+	}
+	return sum
+}
@@ -0,0 +1,120 @@
+package chunking
+
+import (
+	"regexp"
+	"strings"
+)
+
+// markdownHeadingRE matches an ATX Markdown heading line up to H3; deeper
+// headings (H4+) are treated as body text since they're rarely useful as
+// retrieval breadcrumbs.
+var markdownHeadingRE = regexp.MustCompile(`^(#{1,3})\s+(.*)$`)
+
+// MarkdownHeadingChunker splits text on H1-H3 Markdown headings and stamps
+// each resulting chunk with the heading breadcrumb (outermost first) that
+// was in effect for that section. Sections longer than MaxSize are further
+// split with RecursiveCharacterChunker, which inherits the same breadcrumb.
+// Lines inside a fenced (```) code block are never treated as headings, so a
+// "# comment" in a code sample doesn't fragment the section around it.
+type MarkdownHeadingChunker struct{}
+
+func (MarkdownHeadingChunker) Chunk(text string, opts Options) ([]Chunk, error) {
+	maxSize := maxSizeOrDefault(opts)
+
+	var chunks []Chunk
+	var headingPath []string
+
+	sectionStart := 0
+	pos := 0
+	inFence := false
+
+	flush := func(end int) {
+		if end <= sectionStart {
+			return
+		}
+		section := text[sectionStart:end]
+		if strings.TrimSpace(section) == "" {
+			return
+		}
+
+		path := append([]string(nil), headingPath...)
+		for _, s := range splitSpan(section, 0, len(section), recursiveSeparators, maxSize) {
+			chunks = append(chunks, Chunk{
+				Text:        section[s.start:s.end],
+				Start:       sectionStart + s.start,
+				End:         sectionStart + s.end,
+				HeadingPath: path,
+			})
+		}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		lineStart := pos
+		pos += len(line)
+
+		trimmed := strings.TrimRight(line, "\n")
+		if strings.HasPrefix(strings.TrimSpace(trimmed), "```") {
+			inFence = !inFence
+			continue
+		}
+
+		if inFence {
+			continue
+		}
+
+		if m := markdownHeadingRE.FindStringSubmatch(trimmed); m != nil {
+			flush(lineStart)
+			sectionStart = pos
+
+			level := len(m[1])
+			title := strings.TrimSpace(m[2])
+			if level > len(headingPath) {
+				for len(headingPath) < level-1 {
+					headingPath = append(headingPath, "")
+				}
+				headingPath = append(headingPath, title)
+			} else {
+				headingPath = append(headingPath[:level-1], title)
+			}
+		}
+	}
+	flush(len(text))
+
+	return mergeAdjacentChunks(chunks, maxSize), nil
+}
+
+// mergeAdjacentChunks coalesces consecutive chunks that share the same
+// heading breadcrumb and together still fit under maxSize, so a run of short
+// sections under the same heading doesn't get fragmented one-per-line.
+func mergeAdjacentChunks(chunks []Chunk, maxSize int) []Chunk {
+	if len(chunks) == 0 {
+		return chunks
+	}
+
+	merged := []Chunk{chunks[0]}
+	for _, c := range chunks[1:] {
+		last := &merged[len(merged)-1]
+		sameHeading := len(last.HeadingPath) == len(c.HeadingPath)
+		if sameHeading {
+			for i := range last.HeadingPath {
+				if last.HeadingPath[i] != c.HeadingPath[i] {
+					sameHeading = false
+					break
+				}
+			}
+		}
+
+		if sameHeading && c.End-last.Start <= maxSize {
+			last.Text += c.Text
+			last.End = c.End
+			continue
+		}
+
+		merged = append(merged, c)
+	}
+
+	return merged
+}
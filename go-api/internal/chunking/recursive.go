@@ -0,0 +1,110 @@
+package chunking
+
+import "strings"
+
+// recursiveSeparators is the descending list of split points tried in order:
+// paragraph breaks, line breaks, sentence ends, words, then a hard
+// character-by-character split as the last resort.
+var recursiveSeparators = []string{"\n\n", "\n", ". ", " ", ""}
+
+// span is a byte range [start, end) into an original string.
+type span struct {
+	start, end int
+}
+
+// RecursiveCharacterChunker splits on a descending list of separators,
+// recursing into a finer separator whenever a piece still exceeds MaxSize,
+// then greedily reassembles the resulting pieces into chunks up to MaxSize
+// with Overlap characters of context carried into the next chunk.
+type RecursiveCharacterChunker struct{}
+
+func (RecursiveCharacterChunker) Chunk(text string, opts Options) ([]Chunk, error) {
+	maxSize := maxSizeOrDefault(opts)
+	overlap := opts.Overlap
+	if overlap < 0 || overlap >= maxSize {
+		overlap = 0
+	}
+
+	spans := splitSpan(text, 0, len(text), recursiveSeparators, maxSize)
+	return mergeSpans(text, spans, maxSize, overlap), nil
+}
+
+// splitSpan recursively splits text[start:end] on seps[0], falling through to
+// seps[1:] for any resulting piece still longer than maxSize. Separators
+// stay attached to the piece that precedes them.
+func splitSpan(text string, start, end int, seps []string, maxSize int) []span {
+	if end-start <= maxSize || len(seps) == 0 {
+		return []span{{start, end}}
+	}
+
+	sep := seps[0]
+	rest := seps[1:]
+
+	if sep == "" {
+		var spans []span
+		for s := start; s < end; s += maxSize {
+			e := s + maxSize
+			if e > end {
+				e = end
+			}
+			spans = append(spans, span{s, e})
+		}
+		return spans
+	}
+
+	var spans []span
+	pos := start
+	for _, part := range strings.SplitAfter(text[start:end], sep) {
+		if part == "" {
+			continue
+		}
+		partStart := pos
+		partEnd := pos + len(part)
+		pos = partEnd
+
+		if partEnd-partStart > maxSize {
+			spans = append(spans, splitSpan(text, partStart, partEnd, rest, maxSize)...)
+		} else {
+			spans = append(spans, span{partStart, partEnd})
+		}
+	}
+	return spans
+}
+
+// mergeSpans greedily packs adjacent leaf spans into chunks no larger than
+// maxSize, backing the start of each following chunk up by roughly overlap
+// characters so consecutive chunks share trailing/leading context.
+func mergeSpans(text string, spans []span, maxSize, overlap int) []Chunk {
+	var chunks []Chunk
+
+	i := 0
+	for i < len(spans) {
+		start := spans[i].start
+		end := spans[i].end
+
+		j := i + 1
+		for j < len(spans) && spans[j].end-start <= maxSize {
+			end = spans[j].end
+			j++
+		}
+
+		chunks = append(chunks, Chunk{Text: text[start:end], Start: start, End: end})
+
+		if j >= len(spans) {
+			break
+		}
+
+		next := j
+		if overlap > 0 {
+			for k := j - 1; k > i; k-- {
+				if end-spans[k].start >= overlap {
+					next = k
+					break
+				}
+			}
+		}
+		i = next
+	}
+
+	return chunks
+}
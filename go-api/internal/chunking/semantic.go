@@ -0,0 +1,149 @@
+package chunking
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+)
+
+// sentenceBoundaryRE splits on sentence-ending punctuation followed by
+// whitespace, keeping the punctuation with the preceding sentence.
+var sentenceBoundaryRE = regexp.MustCompile(`[.!?]+\s+`)
+
+// semanticWindowRadius is how many neighboring sentences on each side are
+// combined with a sentence before embedding it, smoothing out per-sentence
+// noise in the distance signal (Greg Kamradt's "combined sentence" trick).
+const semanticWindowRadius = 1
+
+// semanticPercentile is the distance-percentile cutoff above which an
+// inter-window jump is treated as a topic boundary.
+const semanticPercentile = 0.95
+
+// SemanticChunker embeds a sliding window around each sentence, measures the
+// cosine distance between consecutive windows, and cuts wherever that
+// distance exceeds the semanticPercentile-th percentile of all distances in
+// the document - the local maxima that mark topic shifts. Requires
+// Options.Embedder; MaxSize is used only as a safety cap on otherwise
+// unbounded semantic groups.
+type SemanticChunker struct{}
+
+func (SemanticChunker) Chunk(text string, opts Options) ([]Chunk, error) {
+	if opts.Embedder == nil {
+		return nil, fmt.Errorf("semantic chunking requires an Embedder")
+	}
+	maxSize := maxSizeOrDefault(opts)
+
+	sentences := splitSentences(text)
+	if len(sentences) == 0 {
+		return nil, nil
+	}
+	if len(sentences) == 1 {
+		return []Chunk{{Text: sentences[0].text, Start: sentences[0].start, End: sentences[0].end}}, nil
+	}
+
+	windows := make([]string, len(sentences))
+	for i := range sentences {
+		lo := i - semanticWindowRadius
+		if lo < 0 {
+			lo = 0
+		}
+		hi := i + semanticWindowRadius
+		if hi > len(sentences)-1 {
+			hi = len(sentences) - 1
+		}
+		combined := ""
+		for j := lo; j <= hi; j++ {
+			combined += sentences[j].text
+		}
+		windows[i] = combined
+	}
+
+	embeddings, err := opts.Embedder.GetEmbeddings(windows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed sentence windows: %w", err)
+	}
+
+	distances := make([]float64, len(sentences)-1)
+	for i := 0; i < len(distances); i++ {
+		distances[i] = cosineDistance(embeddings[i], embeddings[i+1])
+	}
+
+	threshold := percentile(distances, semanticPercentile)
+
+	var chunks []Chunk
+	groupStart := 0
+	for i, s := range sentences {
+		isBoundary := i < len(distances) && distances[i] > threshold
+		isTooBig := s.end-sentences[groupStart].start > maxSize
+		if i == len(sentences)-1 || isBoundary || isTooBig {
+			start := sentences[groupStart].start
+			end := s.end
+			chunks = append(chunks, Chunk{Text: text[start:end], Start: start, End: end})
+			groupStart = i + 1
+		}
+	}
+
+	return chunks, nil
+}
+
+type sentenceSpan struct {
+	text       string
+	start, end int
+}
+
+// splitSentences breaks text on sentence-ending punctuation, preserving byte
+// offsets into the original string.
+func splitSentences(text string) []sentenceSpan {
+	var spans []sentenceSpan
+
+	pos := 0
+	for _, loc := range sentenceBoundaryRE.FindAllStringIndex(text, -1) {
+		end := loc[1]
+		if s := text[pos:end]; len(s) > 0 {
+			spans = append(spans, sentenceSpan{text: s, start: pos, end: end})
+		}
+		pos = end
+	}
+	if pos < len(text) {
+		spans = append(spans, sentenceSpan{text: text[pos:], start: pos, end: len(text)})
+	}
+
+	return spans
+}
+
+// cosineDistance is 1 - cosine similarity; 0 means identical direction, 2
+// means opposite.
+func cosineDistance(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	return 1 - dot/(math.Sqrt(normA)*math.Sqrt(normB))
+}
+
+// percentile returns the p-th percentile (0..1) of values using
+// nearest-rank interpolation. Returns +Inf for an empty slice so no distance
+// ever exceeds it.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return math.Inf(1)
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
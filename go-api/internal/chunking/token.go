@@ -0,0 +1,52 @@
+package chunking
+
+import "fmt"
+
+// TokenChunker splits text into chunks bounded by token count rather than
+// character count, using the embedding model's own tokenizer (the same
+// /embeddings/tokens call LateChunker uses for token offsets) so boundaries
+// fall between tokens instead of risking a cut through the middle of one, as
+// a character-offset split occasionally does near multi-byte or subword
+// tokens. Unlike every other strategy here, Options.MaxSize and
+// Options.Overlap count tokens, not characters.
+type TokenChunker struct{}
+
+func (TokenChunker) Chunk(text string, opts Options) ([]Chunk, error) {
+	if opts.TokenEmbedder == nil {
+		return nil, fmt.Errorf("token-based chunking requires a TokenEmbedder")
+	}
+
+	maxTokens := maxSizeOrDefault(opts)
+	overlap := opts.Overlap
+	if overlap < 0 || overlap >= maxTokens {
+		overlap = 0
+	}
+
+	offsets, _, err := opts.TokenEmbedder.GetTokenEmbeddings(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize text: %w", err)
+	}
+	if len(offsets) == 0 {
+		return nil, nil
+	}
+
+	var chunks []Chunk
+	i := 0
+	for i < len(offsets) {
+		j := i + maxTokens
+		if j > len(offsets) {
+			j = len(offsets)
+		}
+
+		start := offsets[i][0]
+		end := offsets[j-1][1]
+		chunks = append(chunks, Chunk{Text: text[start:end], Start: start, End: end})
+
+		if j >= len(offsets) {
+			break
+		}
+		i = j - overlap
+	}
+
+	return chunks, nil
+}
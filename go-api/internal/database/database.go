@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"github.com/kavishankarks/document-hub/go-api/internal/models"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/vector"
 )
 
 var DB *gorm.DB
@@ -48,6 +49,8 @@ func Initialize() (*gorm.DB, error) {
 		&models.DocumentChunk{},
 		&models.PipelineRun{},
 		&models.CurriculumTopic{},
+		&models.PipelineJob{},
+		&models.PipelineLogArtifact{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
@@ -76,6 +79,36 @@ func Initialize() (*gorm.DB, error) {
 		ON document_chunks USING hnsw(embedding vector_cosine_ops)
 	`)
 
+	// Add a document-level embedding column (see vector.Document.Embedding
+	// and vector.Store.SearchDocuments) so pgvector can do the same
+	// coarse-to-fine document-then-chunk retrieval Milvus does.
+	db.Exec(fmt.Sprintf(`ALTER TABLE documents ADD COLUMN IF NOT EXISTS embedding vector(%d)`, vector.Dim))
+
+	db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_documents_embedding_hnsw
+		ON documents USING hnsw(embedding vector_cosine_ops)
+	`)
+
+	// Add an optional caller-assigned identifier (see vector.Document.
+	// ExternalID) UpsertDocument can match an existing document by, for
+	// callers without a stable source_url.
+	db.Exec(`ALTER TABLE documents ADD COLUMN IF NOT EXISTS external_id VARCHAR(256)`)
+
+	db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_documents_external_id
+		ON documents (external_id)
+	`)
+
+	// Add a chunk content fingerprint (see vector.chunkFingerprint and
+	// vector.Store.UpsertDocument) so a re-ingest can diff against what's
+	// already stored instead of deleting and recreating every chunk.
+	db.Exec(`ALTER TABLE document_chunks ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(64)`)
+
+	db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_chunks_fingerprint
+		ON document_chunks (fingerprint)
+	`)
+
 	// Create index on pipeline runs for efficient status queries
 	db.Exec(`
 		CREATE INDEX IF NOT EXISTS idx_pipeline_runs_status
@@ -88,6 +121,18 @@ func Initialize() (*gorm.DB, error) {
 		ON curriculum_topics (pipeline_run_id, status)
 	`)
 
+	// Create index for job queue workers claiming ready work
+	db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_pipeline_jobs_claim
+		ON pipeline_jobs (status, next_attempt_at)
+	`)
+
+	// Create index for looking up a run's jobs (recovery, cancellation)
+	db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_pipeline_jobs_run
+		ON pipeline_jobs (pipeline_run_id, topic_id, stage)
+	`)
+
 	DB = db
 	return db, nil
 }
@@ -2,13 +2,17 @@ package embedding_client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
 )
 
 type EmbeddingClient struct {
@@ -73,27 +77,83 @@ func NewClient() *EmbeddingClient {
 	}
 }
 
+// postJSON issues a POST with body against path using ctx, so a cancelled or
+// timed-out ctx aborts the in-flight request instead of leaving it to run to
+// completion. Every context-aware method below goes through this.
+func (c *EmbeddingClient) postJSON(ctx context.Context, path string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.baseURL, path), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errs.WrapRetryable(errs.CodeEmbeddingUpstream, "failed to call embedding service", err)
+	}
+	return resp, nil
+}
+
+// classifyHTTPError turns a non-200 response into an *errs.Error, marking
+// 429/5xx retryable (transient overload, upstream restart, etc.) and
+// everything else (4xx other than 429, which means our request itself was
+// bad) terminal - a caller's retry loop shouldn't keep resending a request
+// the service has already told us is malformed. A 429's Retry-After header,
+// if present, is parsed and attached so the retry loop can honor it instead
+// of guessing.
+func classifyHTTPError(resp *http.Response, body []byte) error {
+	message := fmt.Sprintf("embedding service returned %d", resp.StatusCode)
+	cause := fmt.Errorf("%s", body)
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > 0 {
+			return errs.WrapRetryableAfter(errs.CodeEmbeddingUpstream, message, cause, retryAfter)
+		}
+		return errs.WrapRetryable(errs.CodeEmbeddingUpstream, message, cause)
+	}
+
+	return errs.Wrap(errs.CodeEmbeddingUpstream, message, cause)
+}
+
+// parseRetryAfter parses an HTTP Retry-After header's delta-seconds form
+// (e.g. "Retry-After: 2"). The HTTP-date form is deliberately not handled
+// here - none of the upstreams this client talks to send it - so an
+// unparseable or empty header just falls back to the caller's own backoff.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // GetEmbeddings retrieves embeddings for the given texts
 func (c *EmbeddingClient) GetEmbeddings(texts []string) ([][]float32, error) {
+	return c.GetEmbeddingsContext(context.Background(), texts)
+}
+
+// GetEmbeddingsContext is GetEmbeddings with a caller-supplied context, so a
+// pipeline run that's been cancelled aborts the request instead of waiting
+// for it to finish.
+func (c *EmbeddingClient) GetEmbeddingsContext(ctx context.Context, texts []string) ([][]float32, error) {
 	reqBody := EmbeddingRequest{Texts: texts}
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		fmt.Sprintf("%s/api/v1/embeddings", c.baseURL),
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	resp, err := c.postJSON(ctx, "/api/v1/embeddings", "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to call embedding service: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("embedding service returned %d: %s", resp.StatusCode, string(body))
+		return nil, classifyHTTPError(resp, body)
 	}
 
 	var embResp EmbeddingResponse
@@ -121,13 +181,13 @@ func (c *EmbeddingClient) ChunkText(text string, chunkSize int) ([]string, error
 		bytes.NewBuffer(jsonData),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call embedding service: %w", err)
+		return nil, errs.WrapRetryable(errs.CodeEmbeddingUpstream, "failed to call embedding service", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("embedding service returned %d: %s", resp.StatusCode, string(body))
+		return nil, classifyHTTPError(resp, body)
 	}
 
 	var chunkResp ChunkResponse
@@ -155,6 +215,11 @@ func (c *EmbeddingClient) HealthCheck() error {
 
 // EnrichTopic enriches a curriculum topic with web search results
 func (c *EmbeddingClient) EnrichTopic(topicName string, maxResults int) (map[string]interface{}, error) {
+	return c.EnrichTopicContext(context.Background(), topicName, maxResults)
+}
+
+// EnrichTopicContext is EnrichTopic with a caller-supplied context.
+func (c *EmbeddingClient) EnrichTopicContext(ctx context.Context, topicName string, maxResults int) (map[string]interface{}, error) {
 	reqBody := EnrichTopicRequest{
 		TopicName:  topicName,
 		MaxResults: maxResults,
@@ -164,19 +229,15 @@ func (c *EmbeddingClient) EnrichTopic(topicName string, maxResults int) (map[str
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		fmt.Sprintf("%s/api/v1/enrich-topic", c.baseURL),
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	resp, err := c.postJSON(ctx, "/api/v1/enrich-topic", "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to call embedding service: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("embedding service returned %d: %s", resp.StatusCode, string(body))
+		return nil, classifyHTTPError(resp, body)
 	}
 
 	var enrichResp map[string]interface{}
@@ -189,6 +250,11 @@ func (c *EmbeddingClient) EnrichTopic(topicName string, maxResults int) (map[str
 
 // NormalizeText normalizes text content
 func (c *EmbeddingClient) NormalizeText(text string, cleanHTML bool) (string, error) {
+	return c.NormalizeTextContext(context.Background(), text, cleanHTML)
+}
+
+// NormalizeTextContext is NormalizeText with a caller-supplied context.
+func (c *EmbeddingClient) NormalizeTextContext(ctx context.Context, text string, cleanHTML bool) (string, error) {
 	reqBody := NormalizeRequest{
 		Text:          text,
 		CleanHTMLTags: cleanHTML,
@@ -198,19 +264,15 @@ func (c *EmbeddingClient) NormalizeText(text string, cleanHTML bool) (string, er
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		fmt.Sprintf("%s/api/v1/normalize", c.baseURL),
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	resp, err := c.postJSON(ctx, "/api/v1/normalize", "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to call embedding service: %w", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("embedding service returned %d: %s", resp.StatusCode, string(body))
+		return "", classifyHTTPError(resp, body)
 	}
 
 	var normalizeResp NormalizeResponse
@@ -221,6 +283,52 @@ func (c *EmbeddingClient) NormalizeText(text string, cleanHTML bool) (string, er
 	return normalizeResp.NormalizedText, nil
 }
 
+type TokenEmbeddingRequest struct {
+	Text string `json:"text"`
+}
+
+type TokenEmbeddingResponse struct {
+	// Offsets holds each token's [start, end) byte range into the request's
+	// text, in the same order as Embeddings.
+	Offsets    [][2]int    `json:"offsets"`
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// GetTokenEmbeddings tokenizes text with the embedding model's own tokenizer
+// and returns one contextual embedding per token, for chunking.TokenChunker
+// and chunking.LateChunker.
+func (c *EmbeddingClient) GetTokenEmbeddings(text string) ([][2]int, [][]float32, error) {
+	return c.GetTokenEmbeddingsContext(context.Background(), text)
+}
+
+// GetTokenEmbeddingsContext is GetTokenEmbeddings with a caller-supplied
+// context.
+func (c *EmbeddingClient) GetTokenEmbeddingsContext(ctx context.Context, text string) ([][2]int, [][]float32, error) {
+	reqBody := TokenEmbeddingRequest{Text: text}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := c.postJSON(ctx, "/api/v1/embeddings/tokens", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, nil, classifyHTTPError(resp, body)
+	}
+
+	var tokenResp TokenEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return tokenResp.Offsets, tokenResp.Embeddings, nil
+}
+
 type ConvertResponse struct {
 	Markdown string `json:"markdown"`
 	Filename string `json:"filename"`
@@ -250,13 +358,13 @@ func (c *EmbeddingClient) ConvertDocument(filename string, content io.Reader) (s
 		body,
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to call embedding service: %w", err)
+		return "", errs.WrapRetryable(errs.CodeEmbeddingUpstream, "failed to call embedding service", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("embedding service returned %d: %s", resp.StatusCode, string(body))
+		return "", classifyHTTPError(resp, body)
 	}
 
 	var convertResp ConvertResponse
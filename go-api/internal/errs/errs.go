@@ -0,0 +1,120 @@
+// Package errs defines a typed error taxonomy shared by the vector store,
+// embedding client, LLM providers, and pipeline orchestrator, so handlers can
+// map failures to the right HTTP status instead of returning 500 for
+// everything.
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Code identifies the class of failure independent of the message text, so
+// callers can branch on it with errors.As instead of string-matching.
+type Code string
+
+const (
+	// CodeDuplicate means a create/insert collided with an existing unique
+	// value (e.g. a document title).
+	CodeDuplicate Code = "duplicate"
+	// CodeNotFound means the requested resource doesn't exist.
+	CodeNotFound Code = "not_found"
+	// CodeEmbeddingUpstream means the embedding microservice failed or was
+	// unreachable.
+	CodeEmbeddingUpstream Code = "embedding_upstream"
+	// CodeVectorStore means the Milvus/pgvector backend failed.
+	CodeVectorStore Code = "vector_store"
+	// CodeLLMSafetyBlocked means a generation request was blocked by the
+	// provider's content safety filter rather than failing outright.
+	CodeLLMSafetyBlocked Code = "llm_safety_blocked"
+	// CodeLLMUpstream means an LLM provider's API failed or was
+	// unreachable.
+	CodeLLMUpstream Code = "llm_upstream"
+	// CodeCancelled means the operation was cancelled via context, not a
+	// failure of the work itself.
+	CodeCancelled Code = "cancelled"
+	// CodeValidation means the request itself was malformed or failed a
+	// business rule.
+	CodeValidation Code = "validation"
+	// CodeForbidden means the caller isn't authorized to perform the
+	// requested operation (e.g. a missing or wrong admin credential).
+	CodeForbidden Code = "forbidden"
+)
+
+// Error is a typed, wrappable error carrying a Code for HTTP-status mapping
+// and a Retryable hint for callers doing retry-with-backoff.
+type Error struct {
+	Code      Code
+	Message   string
+	Cause     error
+	Retryable bool
+
+	// RetryAfter is how long a retrying caller should wait before trying
+	// again, when the upstream told us explicitly (e.g. a 429's
+	// Retry-After header). Zero means "no explicit hint, use your own
+	// backoff schedule". Only meaningful when Retryable is true.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+// Unwrap lets errors.Is/errors.As see through to the underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// New creates an Error with no wrapped cause.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Newf is New with fmt.Sprintf-style formatting.
+func Newf(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap creates an Error that wraps cause, preserving it for errors.Is/As.
+func Wrap(code Code, message string, cause error) *Error {
+	return &Error{Code: code, Message: message, Cause: cause}
+}
+
+// Wrapf is Wrap with fmt.Sprintf-style formatting.
+func Wrapf(code Code, cause error, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...), Cause: cause}
+}
+
+// WrapRetryable is Wrap for failures a caller should retry (e.g. a timed-out
+// upstream call).
+func WrapRetryable(code Code, message string, cause error) *Error {
+	return &Error{Code: code, Message: message, Cause: cause, Retryable: true}
+}
+
+// WrapRetryableAfter is WrapRetryable for a failure whose upstream told us
+// exactly how long to wait before retrying (e.g. an HTTP 429's Retry-After
+// header), so a caller's backoff loop can honor that instead of guessing.
+func WrapRetryableAfter(code Code, message string, cause error, retryAfter time.Duration) *Error {
+	return &Error{Code: code, Message: message, Cause: cause, Retryable: true, RetryAfter: retryAfter}
+}
+
+// Is reports whether err is (or wraps) an *Error with the given code.
+func Is(err error, code Code) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code == code
+	}
+	return false
+}
+
+// As is a thin convenience wrapper around errors.As for *Error, so callers
+// don't need to spell out the pointer type themselves.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
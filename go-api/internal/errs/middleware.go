@@ -0,0 +1,66 @@
+package errs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// httpStatus maps a Code to the HTTP status handlers should respond with.
+// Codes with no entry (or errors that aren't *Error at all) fall back to 500.
+var httpStatus = map[Code]int{
+	CodeDuplicate:         fiber.StatusConflict,
+	CodeNotFound:          fiber.StatusNotFound,
+	CodeEmbeddingUpstream: fiber.StatusBadGateway,
+	CodeLLMUpstream:       fiber.StatusBadGateway,
+	CodeVectorStore:       fiber.StatusInternalServerError,
+	CodeLLMSafetyBlocked:  fiber.StatusUnprocessableEntity,
+	CodeCancelled:         fiber.StatusInternalServerError,
+	CodeValidation:        fiber.StatusUnprocessableEntity,
+	CodeForbidden:         fiber.StatusForbidden,
+}
+
+// Middleware is a Fiber ErrorHandler that maps a handler's returned error to
+// the right HTTP status and a stable JSON body, so handlers can just
+// `return err` instead of hand-rolling a status code and error shape per
+// call site. Wire it in via fiber.Config{ErrorHandler: errs.Middleware}.
+func Middleware(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	code := Code("internal")
+	message := err.Error()
+	retryable := false
+
+	if fe, ok := err.(*fiber.Error); ok {
+		status = fe.Code
+		message = fe.Message
+	} else if e, ok := As(err); ok {
+		code = e.Code
+		message = e.Error()
+		retryable = e.Retryable
+		if s, ok := httpStatus[e.Code]; ok {
+			status = s
+		}
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"code":      code,
+		"message":   message,
+		"retryable": retryable,
+		"trace_id":  traceID(c),
+	})
+}
+
+// traceID returns the request ID set by the requestid middleware, or
+// generates one on the spot if that middleware isn't mounted.
+func traceID(c *fiber.Ctx) string {
+	if id, ok := c.Locals("requestid").(string); ok && id != "" {
+		return id
+	}
+
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/vector"
+)
+
+// defaultBulkEmbeddingBatchSize caps how many chunks (across however many
+// documents) are sent to the embedding service and vector store in a single
+// request, amortizing network cost over large ingests. Override with
+// BULK_EMBEDDING_BATCH_SIZE.
+const defaultBulkEmbeddingBatchSize = 32
+
+// bulkActionLine is the Elasticsearch-style action header that precedes a
+// document line, e.g. `{"index": {}}`.
+type bulkActionLine struct {
+	Index  *struct{} `json:"index,omitempty"`
+	Create *struct{} `json:"create,omitempty"`
+}
+
+// bulkRecord is the self-contained single-line form:
+// `{"action":"create","doc":{...}}`.
+type bulkRecord struct {
+	Action string                       `json:"action"`
+	Doc    models.CreateDocumentRequest `json:"doc"`
+}
+
+// bulkItemResult is streamed back as one NDJSON line per processed item.
+type bulkItemResult struct {
+	Line   int    `json:"line"`
+	ID     int64  `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// pendingBulkDoc is a document whose vector-store record was already created
+// but whose chunks haven't been embedded/upserted yet; it waits here until
+// its batch fills up.
+type pendingBulkDoc struct {
+	line      int
+	docID     int64
+	docType   string
+	createdAt int64
+	chunks    []string
+}
+
+// BulkCreateDocuments godoc
+// @Summary Bulk create documents via NDJSON
+// @Description Accepts newline-delimited JSON, either Elasticsearch-style `{"index":{}}` action lines followed by a document line, or self-contained `{"action":"create","doc":{...}}` records. Streams back one NDJSON `{line,id,status,error}` result per item as it completes.
+// @Tags documents
+// @Accept application/x-ndjson
+// @Produce application/x-ndjson
+// @Param refresh query bool false "Flush the vector index before returning"
+// @Success 200 {string} string "application/x-ndjson"
+// @Failure 400 {object} map[string]string
+// @Router /documents/_bulk [post]
+func (h *Handler) BulkCreateDocuments(c *fiber.Ctx) error {
+	body := c.Body()
+	if len(body) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Request body is empty",
+		})
+	}
+
+	refresh := c.QueryBool("refresh", false)
+
+	c.Set("Content-Type", "application/x-ndjson")
+
+	tenant := tenantID(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		h.processBulkRequest(body, w, refresh, tenant)
+	})
+
+	return nil
+}
+
+func (h *Handler) processBulkRequest(body []byte, w *bufio.Writer, refresh bool, tenant string) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	batchSize := bulkEmbeddingBatchSize()
+	var pending []pendingBulkDoc
+
+	flushBatch := func() {
+		if len(pending) == 0 {
+			return
+		}
+		h.embedAndStoreBulkBatch(pending, w, tenant)
+		pending = nil
+	}
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var action bulkActionLine
+		if json.Unmarshal(raw, &action) == nil && (action.Index != nil || action.Create != nil) {
+			if !scanner.Scan() {
+				writeBulkResult(w, bulkItemResult{Line: lineNum, Status: "error", Error: "missing document line after bulk action"})
+				break
+			}
+			lineNum++
+
+			var doc models.CreateDocumentRequest
+			if err := json.Unmarshal(bytes.TrimSpace(scanner.Bytes()), &doc); err != nil {
+				writeBulkResult(w, bulkItemResult{Line: lineNum, Status: "error", Error: fmt.Sprintf("invalid document JSON: %v", err)})
+				continue
+			}
+
+			pending = h.queueBulkDoc(pending, lineNum, doc, w, tenant)
+		} else {
+			var record bulkRecord
+			if err := json.Unmarshal(raw, &record); err != nil || record.Action == "" {
+				writeBulkResult(w, bulkItemResult{Line: lineNum, Status: "error", Error: "line is neither a valid bulk action nor a self-contained record"})
+				continue
+			}
+
+			pending = h.queueBulkDoc(pending, lineNum, record.Doc, w, tenant)
+		}
+
+		if len(pending) >= batchSize {
+			flushBatch()
+		}
+	}
+
+	flushBatch()
+
+	if err := scanner.Err(); err != nil {
+		writeBulkResult(w, bulkItemResult{Line: lineNum, Status: "error", Error: fmt.Sprintf("failed to read request body: %v", err)})
+	}
+
+	if refresh {
+		if err := h.store.Flush(); err != nil {
+			writeBulkResult(w, bulkItemResult{Status: "error", Error: fmt.Sprintf("failed to refresh index: %v", err)})
+		}
+	}
+}
+
+// queueBulkDoc creates the document record and chunks its content, queuing
+// it for batched embedding. Failures are reported immediately since they
+// don't depend on the rest of the batch.
+func (h *Handler) queueBulkDoc(pending []pendingBulkDoc, line int, req models.CreateDocumentRequest, w *bufio.Writer, tenant string) []pendingBulkDoc {
+	if req.Title == "" || req.Content == "" {
+		writeBulkResult(w, bulkItemResult{Line: line, Status: "error", Error: "title and content are required"})
+		return pending
+	}
+
+	metadataBytes, _ := json.Marshal(req.Metadata)
+	doc := &vector.Document{
+		Title:     req.Title,
+		Content:   req.Content,
+		SourceURL: req.SourceURL,
+		DocType:   req.DocType,
+		Metadata:  string(metadataBytes),
+		Embedding: h.embedDocumentSummary(req.Title, req.Content),
+	}
+
+	docID, err := h.store.CreateDocument(doc, tenant)
+	if err != nil {
+		writeBulkResult(w, bulkItemResult{Line: line, Status: "error", Error: fmt.Sprintf("failed to create document: %v", err)})
+		return pending
+	}
+
+	chunks, err := h.embeddingClient.ChunkText(req.Content, 500)
+	if err != nil {
+		h.store.Delete(docID, tenant)
+		writeBulkResult(w, bulkItemResult{Line: line, ID: docID, Status: "error", Error: fmt.Sprintf("failed to chunk text: %v", err)})
+		return pending
+	}
+
+	return append(pending, pendingBulkDoc{line: line, docID: docID, docType: req.DocType, createdAt: time.Now().Unix(), chunks: chunks})
+}
+
+// embedAndStoreBulkBatch embeds every chunk across the batch's documents in
+// a single request and upserts them in a single call, then reports a result
+// line per document. If either call fails, the whole batch is reported as
+// failed since a partial-document attribution isn't possible from one
+// network round trip.
+func (h *Handler) embedAndStoreBulkBatch(pending []pendingBulkDoc, w *bufio.Writer, tenant string) {
+	var allChunkTexts []string
+	for _, doc := range pending {
+		allChunkTexts = append(allChunkTexts, doc.chunks...)
+	}
+
+	embeddings, err := h.embeddingClient.GetEmbeddings(allChunkTexts)
+	if err != nil {
+		for _, doc := range pending {
+			h.store.Delete(doc.docID, tenant)
+			writeBulkResult(w, bulkItemResult{Line: doc.line, ID: doc.docID, Status: "error", Error: fmt.Sprintf("failed to generate embeddings: %v", err)})
+		}
+		return
+	}
+
+	var chunks []vector.Chunk
+	offset := 0
+	for _, doc := range pending {
+		for i, chunkText := range doc.chunks {
+			chunks = append(chunks, vector.Chunk{
+				DocumentID: doc.docID,
+				ChunkIndex: int64(i),
+				ChunkText:  chunkText,
+				Embedding:  embeddings[offset+i],
+				DocType:    doc.docType,
+				CreatedAt:  doc.createdAt,
+			})
+		}
+		offset += len(doc.chunks)
+	}
+
+	if err := h.store.Upsert(chunks, tenant); err != nil {
+		for _, doc := range pending {
+			h.store.Delete(doc.docID, tenant)
+			writeBulkResult(w, bulkItemResult{Line: doc.line, ID: doc.docID, Status: "error", Error: fmt.Sprintf("failed to store chunks: %v", err)})
+		}
+		return
+	}
+
+	for _, doc := range pending {
+		writeBulkResult(w, bulkItemResult{Line: doc.line, ID: doc.docID, Status: "created"})
+	}
+}
+
+func writeBulkResult(w *bufio.Writer, result bulkItemResult) {
+	line, _ := json.Marshal(result)
+	w.Write(line)
+	w.WriteByte('\n')
+	w.Flush()
+}
+
+func bulkEmbeddingBatchSize() int {
+	if v := os.Getenv("BULK_EMBEDDING_BATCH_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultBulkEmbeddingBatchSize
+}
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/llm"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
 )
@@ -30,6 +33,9 @@ type GenerateRequest struct {
 	IncludeCitations bool    `json:"include_citations"`
 	MinScore         float64 `json:"min_score"`
 	Limit            int     `json:"limit"`
+	RRFK             int     `json:"rrf_k"`             // RRF k constant for hybrid retrieval; 0 uses the default
+	Rerank           bool    `json:"rerank"`            // rerank retrieved context with the configured cross-encoder
+	RerankCandidates int     `json:"rerank_candidates"` // how many fused results to rerank; 0 uses the default
 }
 
 // GenerateResponse represents the generation response
@@ -76,11 +82,31 @@ func (h *GenerateHandler) Generate(c *fiber.Ctx) error {
 		req.MinScore = 0.3
 	}
 
-	// 1. Retrieve relevant context using Hybrid Search
-	// We access the search logic directly from the existing handler
-	results := h.searchHandler.hybridSearch(req.Prompt, req.Limit, req.MinScore)
+	// 1. Retrieve relevant context and build the prompt
+	results, prompt := h.retrieveAndBuildPrompt(req, tenantID(c))
+
+	// 2. Generate content
+	generatedText, err := h.llmProvider.GenerateContent(context.Background(), prompt)
+	if err != nil {
+		return err
+	}
+
+	// 3. Return response
+	return c.JSON(GenerateResponse{
+		GeneratedText: generatedText,
+		Sources:       results,
+	})
+}
+
+// retrieveAndBuildPrompt performs the hybrid-search retrieval and constructs
+// the grounded prompt shared by the blocking and streaming generate routes.
+func (h *GenerateHandler) retrieveAndBuildPrompt(req GenerateRequest, tenant string) ([]models.SearchResult, string) {
+	results := h.searchHandler.hybridSearch(req.Prompt, req.Limit, req.MinScore, HybridSearchOptions{
+		RRFK:             req.RRFK,
+		Rerank:           req.Rerank,
+		RerankCandidates: req.RerankCandidates,
+	}, tenant)
 
-	// 2. Construct the prompt
 	var contextBuilder strings.Builder
 	contextBuilder.WriteString("Context information is below.\n---------------------\n")
 
@@ -96,18 +122,96 @@ func (h *GenerateHandler) Generate(c *fiber.Ctx) error {
 	contextBuilder.WriteString(fmt.Sprintf("Query: %s\n", req.Prompt))
 	contextBuilder.WriteString("Answer: ")
 
-	// 3. Generate content
-	generatedText, err := h.llmProvider.GenerateContent(context.Background(), contextBuilder.String())
-	if err != nil {
-		fmt.Printf("Error generating content: %v\n", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to generate content: %v", err),
+	return results, contextBuilder.String()
+}
+
+// GenerateStream godoc
+// @Summary Generate content using LLM, streamed over SSE
+// @Description Same retrieval and prompting as Generate, but streams the answer token-by-token over Server-Sent Events
+// @Tags generation
+// @Accept json
+// @Produce text/event-stream
+// @Param request body GenerateRequest true "Generation request"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 400,500 {object} map[string]string
+// @Router /generate/stream [post]
+func (h *GenerateHandler) GenerateStream(c *fiber.Ctx) error {
+	if h.llmProvider == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "LLM provider not initialized. Please check your GEMINI_API_KEY configuration.",
 		})
 	}
 
-	// 4. Return response
-	return c.JSON(GenerateResponse{
-		GeneratedText: generatedText,
-		Sources:       results,
+	var req GenerateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Prompt == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Prompt is required",
+		})
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 5
+	}
+	if req.MinScore == 0 {
+		req.MinScore = 0.3
+	}
+
+	results, prompt := h.retrieveAndBuildPrompt(req, tenantID(c))
+
+	ctx := c.Context()
+	streamCtx, cancel := context.WithCancel(ctx)
+
+	tokens, err := h.llmProvider.GenerateContentStream(streamCtx, prompt)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		sourcesJSON, _ := json.Marshal(results)
+		fmt.Fprintf(w, "event: sources\ndata: %s\n\n", sourcesJSON)
+		w.Flush()
+
+		for chunk := range tokens {
+			if chunk.Err != nil {
+				body := fiber.Map{"error": chunk.Err.Error()}
+				if e, ok := errs.As(chunk.Err); ok {
+					body["code"] = e.Code
+					body["retryable"] = e.Retryable
+				}
+				errJSON, _ := json.Marshal(body)
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", errJSON)
+				w.Flush()
+				return
+			}
+
+			if chunk.Done {
+				doneJSON, _ := json.Marshal(fiber.Map{"finish_reason": chunk.FinishReason})
+				fmt.Fprintf(w, "event: done\ndata: %s\n\n", doneJSON)
+				w.Flush()
+				return
+			}
+
+			deltaJSON, _ := json.Marshal(fiber.Map{"text": chunk.Text})
+			fmt.Fprintf(w, "event: token\ndata: %s\n\n", deltaJSON)
+			if err := w.Flush(); err != nil {
+				// Client disconnected; stop generating.
+				return
+			}
+		}
 	})
+
+	return nil
 }
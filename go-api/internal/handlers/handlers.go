@@ -1,26 +1,107 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/chunking"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/embedding_client"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/objectstore"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/reranker"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/sparseembed"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/utils/hal"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/vector"
 )
 
 type Handler struct {
 	embeddingClient *embedding_client.EmbeddingClient
-	milvusClient    *vector.MilvusClient
+	store           vector.Store
+	reranker        *reranker.Client    // nil unless RERANKER_URL is set
+	sparseEmbedder  *sparseembed.Client // nil unless EMBEDDING_SPARSE_URL is set
+	objectStore     objectstore.Store
+	uploadJobs      *uploadJobManager
+	adminKey        string // empty unless ADMIN_API_KEY is set; see requireAdmin
 }
 
-func NewHandler(milvusClient *vector.MilvusClient) *Handler {
-	return &Handler{
+func NewHandler(store vector.Store) (*Handler, error) {
+	objStore, err := objectstore.NewStoreFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize object store: %w", err)
+	}
+
+	h := &Handler{
 		embeddingClient: embedding_client.NewClient(),
-		milvusClient:    milvusClient,
+		store:           store,
+		reranker:        reranker.NewClient(),
+		sparseEmbedder:  sparseembed.NewClient(),
+		objectStore:     objStore,
+		adminKey:        os.Getenv("ADMIN_API_KEY"),
+	}
+	h.uploadJobs = newUploadJobManager(h.processUpload)
+
+	return h, nil
+}
+
+// tenantIDHeader is the request header clients set to scope a call to a
+// specific tenant partition (see vector.MilvusClient.EnsurePartition).
+const tenantIDHeader = "X-Tenant-ID"
+
+// tenantID reads the caller's tenant off the request, or "" for the
+// default/untenanted partition.
+func tenantID(c *fiber.Ctx) string {
+	return c.Get(tenantIDHeader)
+}
+
+// adminKeyHeader is the request header an operator-only call (e.g. tenant
+// offboarding) must present, checked against ADMIN_API_KEY.
+const adminKeyHeader = "X-Admin-Key"
+
+// requireAdmin gates operator-only endpoints (tenant partition
+// create/drop) behind a shared admin credential, since tenantID is just a
+// caller-supplied header and can't be trusted to authorize destructive,
+// cross-tenant-affecting operations on its own. Fails closed: if
+// ADMIN_API_KEY isn't configured, every call is rejected rather than left
+// open.
+func (h *Handler) requireAdmin(c *fiber.Ctx) error {
+	if h.adminKey == "" || subtle.ConstantTimeCompare([]byte(c.Get(adminKeyHeader)), []byte(h.adminKey)) != 1 {
+		return errs.New(errs.CodeForbidden, "admin credential required")
 	}
+	return nil
+}
+
+// documentSummaryLen bounds how much of a document's content feeds its
+// document-level embedding (see vector.Document.Embedding): enough to
+// capture the gist for coarse document-level search, short enough that
+// embedding it costs about the same as embedding one chunk.
+const documentSummaryLen = 500
+
+// embedDocumentSummary computes a document-level embedding over the title
+// and a leading slice of the content, for vector.Document.Embedding and
+// Store.SearchDocuments. A failure here logs and returns nil rather than
+// failing document creation: the backend falls back to the zero vector,
+// which only costs that document a place in document-level search results,
+// not chunk-level search or retrieval.
+func (h *Handler) embedDocumentSummary(title, content string) []float32 {
+	summary := title
+	if len(content) > documentSummaryLen {
+		summary += "\n" + content[:documentSummaryLen]
+	} else if content != "" {
+		summary += "\n" + content
+	}
+
+	embeddings, err := h.embeddingClient.GetEmbeddings([]string{summary})
+	if err != nil || len(embeddings) == 0 {
+		fmt.Printf("Warning: failed to compute document-level embedding: %v\n", err)
+		return nil
+	}
+	return embeddings[0]
 }
 
 // CreateDocument godoc
@@ -35,16 +116,9 @@ func NewHandler(milvusClient *vector.MilvusClient) *Handler {
 func (h *Handler) CreateDocument(c *fiber.Ctx) error {
 	var req models.CreateDocumentRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return errs.New(errs.CodeValidation, "invalid request body")
 	}
 
-	// Create document in Milvus
-	// Note: MilvusClient.CreateDocument checks for duplicates by title (if implemented)
-	// or we rely on unique constraint if any.
-	// Our CreateDocument implementation does check for existing title.
-
 	metadataBytes, _ := json.Marshal(req.Metadata)
 
 	milvusDoc := &vector.Document{
@@ -53,54 +127,89 @@ func (h *Handler) CreateDocument(c *fiber.Ctx) error {
 		SourceURL: req.SourceURL,
 		DocType:   req.DocType,
 		Metadata:  string(metadataBytes),
+		Embedding: h.embedDocumentSummary(req.Title, req.Content),
 	}
 
-	docID, err := h.milvusClient.CreateDocument(milvusDoc)
+	docID, err := h.store.CreateDocument(milvusDoc, tenantID(c))
 	if err != nil {
-		// Check if error is duplicate
-		// This depends on how CreateDocument returns error.
-		// Assuming generic error for now, but we could improve this.
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create document: %v", err),
-		})
+		return err
+	}
+
+	strategy := req.ChunkStrategy
+	if strategy == "" {
+		strategy = chunking.DefaultStrategy
+	}
+
+	chunker, ok := chunking.Get(strategy)
+	if !ok {
+		h.store.Delete(docID, tenantID(c))
+		return errs.Newf(errs.CodeValidation, "unknown chunk_strategy %q", req.ChunkStrategy)
 	}
 
 	// Chunk the content
-	chunks, err := h.embeddingClient.ChunkText(req.Content, 500)
+	docChunks, err := chunker.Chunk(req.Content, chunking.Options{
+		MaxSize:       req.ChunkSize,
+		Overlap:       req.ChunkOverlap,
+		Embedder:      h.embeddingClient,
+		TokenEmbedder: h.embeddingClient,
+	})
 	if err != nil {
 		// Try to cleanup
-		h.milvusClient.DeleteDocument(docID)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to chunk text: %v", err),
-		})
+		h.store.Delete(docID, tenantID(c))
+		return errs.Wrap(errs.CodeValidation, "failed to chunk document content", err)
 	}
 
-	// Get embeddings for all chunks
-	embeddings, err := h.embeddingClient.GetEmbeddings(chunks)
-	if err != nil {
-		h.milvusClient.DeleteDocument(docID)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to generate embeddings: %v", err),
-		})
+	// LateChunker already populates each Chunk.Embedding by pooling token
+	// embeddings from one whole-document call; every other strategy leaves
+	// it nil and needs its chunk texts embedded here instead.
+	embeddings := make([][]float32, len(docChunks))
+	var toEmbed []string
+	var toEmbedIdx []int
+	for i, dc := range docChunks {
+		if dc.Embedding != nil {
+			embeddings[i] = dc.Embedding
+			continue
+		}
+		toEmbed = append(toEmbed, dc.Text)
+		toEmbedIdx = append(toEmbedIdx, i)
+	}
+	if len(toEmbed) > 0 {
+		embedded, err := h.embeddingClient.GetEmbeddings(toEmbed)
+		if err != nil {
+			h.store.Delete(docID, tenantID(c))
+			return err
+		}
+		for i, idx := range toEmbedIdx {
+			embeddings[idx] = embedded[i]
+		}
 	}
 
 	// Create chunk records for Milvus
+	now := time.Now().Unix()
 	var milvusChunks []vector.Chunk
-	for i, chunk := range chunks {
+	for i, dc := range docChunks {
+		metadataBytes, _ := json.Marshal(fiber.Map{
+			"start":          dc.Start,
+			"end":            dc.End,
+			"heading_path":   dc.HeadingPath,
+			"chunk_strategy": strategy,
+		})
+
 		milvusChunks = append(milvusChunks, vector.Chunk{
 			DocumentID: docID,
 			ChunkIndex: int64(i),
-			ChunkText:  chunk,
+			ChunkText:  dc.Text,
 			Embedding:  embeddings[i],
+			Metadata:   string(metadataBytes),
+			DocType:    req.DocType,
+			CreatedAt:  now,
 		})
 	}
 
 	// Store chunks in Milvus
-	if err := h.milvusClient.AddChunks(milvusChunks); err != nil {
-		h.milvusClient.DeleteDocument(docID)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to store chunks in vector DB: %v", err),
-		})
+	if err := h.store.Upsert(milvusChunks, tenantID(c)); err != nil {
+		h.store.Delete(docID, tenantID(c))
+		return err
 	}
 
 	// Construct response
@@ -116,6 +225,114 @@ func (h *Handler) CreateDocument(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(respDoc)
 }
 
+// UpsertDocument godoc
+// @Summary Idempotently create or re-ingest a document
+// @Description Matches an existing document by external_id (if set) or source_url; re-chunks content and diffs the result against what's already stored by content fingerprint, so unchanged chunks stay searchable throughout instead of disappearing during a delete-then-recreate reindex.
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param body body models.CreateDocumentRequest true "Document"
+// @Success 200 {object} models.Document
+// @Failure 400,500 {object} map[string]interface{}
+// @Router /documents/upsert [post]
+func (h *Handler) UpsertDocument(c *fiber.Ctx) error {
+	var req models.CreateDocumentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errs.New(errs.CodeValidation, "invalid request body")
+	}
+
+	metadataBytes, _ := json.Marshal(req.Metadata)
+
+	doc := &vector.Document{
+		Title:      req.Title,
+		Content:    req.Content,
+		SourceURL:  req.SourceURL,
+		DocType:    req.DocType,
+		Metadata:   string(metadataBytes),
+		ExternalID: req.ExternalID,
+		Embedding:  h.embedDocumentSummary(req.Title, req.Content),
+	}
+
+	strategy := req.ChunkStrategy
+	if strategy == "" {
+		strategy = chunking.DefaultStrategy
+	}
+
+	chunker, ok := chunking.Get(strategy)
+	if !ok {
+		return errs.Newf(errs.CodeValidation, "unknown chunk_strategy %q", req.ChunkStrategy)
+	}
+
+	docChunks, err := chunker.Chunk(req.Content, chunking.Options{
+		MaxSize:       req.ChunkSize,
+		Overlap:       req.ChunkOverlap,
+		Embedder:      h.embeddingClient,
+		TokenEmbedder: h.embeddingClient,
+	})
+	if err != nil {
+		return errs.Wrap(errs.CodeValidation, "failed to chunk document content", err)
+	}
+
+	// LateChunker already populates each Chunk.Embedding; every other
+	// strategy leaves it nil and needs its chunk texts embedded here.
+	embeddings := make([][]float32, len(docChunks))
+	var toEmbed []string
+	var toEmbedIdx []int
+	for i, dc := range docChunks {
+		if dc.Embedding != nil {
+			embeddings[i] = dc.Embedding
+			continue
+		}
+		toEmbed = append(toEmbed, dc.Text)
+		toEmbedIdx = append(toEmbedIdx, i)
+	}
+	if len(toEmbed) > 0 {
+		embedded, err := h.embeddingClient.GetEmbeddings(toEmbed)
+		if err != nil {
+			return err
+		}
+		for i, idx := range toEmbedIdx {
+			embeddings[idx] = embedded[i]
+		}
+	}
+
+	now := time.Now().Unix()
+	chunks := make([]vector.Chunk, len(docChunks))
+	for i, dc := range docChunks {
+		metadataBytes, _ := json.Marshal(fiber.Map{
+			"start":          dc.Start,
+			"end":            dc.End,
+			"heading_path":   dc.HeadingPath,
+			"chunk_strategy": strategy,
+		})
+
+		chunks[i] = vector.Chunk{
+			ChunkIndex: int64(i),
+			ChunkText:  dc.Text,
+			Embedding:  embeddings[i],
+			Metadata:   string(metadataBytes),
+			DocType:    req.DocType,
+			CreatedAt:  now,
+		}
+	}
+
+	docID, err := h.store.UpsertDocument(doc, chunks, tenantID(c))
+	if err != nil {
+		return err
+	}
+
+	respDoc := models.Document{
+		ID:        uint(docID),
+		Title:     req.Title,
+		Content:   req.Content,
+		SourceURL: req.SourceURL,
+		DocType:   req.DocType,
+		Metadata:  req.Metadata,
+	}
+
+	return c.Status(fiber.StatusOK).JSON(respDoc)
+}
+
 // GetDocument godoc
 // @Summary Get document by ID
 // @Tags documents
@@ -126,16 +343,12 @@ func (h *Handler) CreateDocument(c *fiber.Ctx) error {
 func (h *Handler) GetDocument(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid document ID",
-		})
+		return errs.New(errs.CodeValidation, "invalid document ID")
 	}
 
-	milvusDoc, err := h.milvusClient.GetDocument(int64(id))
+	milvusDoc, err := h.store.GetDocument(int64(id), tenantID(c))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Document not found",
-		})
+		return err
 	}
 
 	var metadata map[string]interface{}
@@ -150,29 +363,38 @@ func (h *Handler) GetDocument(c *fiber.Ctx) error {
 		Metadata:  metadata,
 	}
 
-	return c.JSON(doc)
+	return hal.SendHAL(c, fiber.StatusOK, fiber.Map{"document": doc}, hal.DocumentLinks(doc.ID))
 }
 
 // ListDocuments godoc
 // @Summary List documents
+// @Description Keyset-paginated: pass the previous response's next_cursor as cursor to fetch the next page. total is an approximate count, cached for up to 30s.
 // @Tags documents
-// @Param skip query int false "Skip"
+// @Param cursor query int false "Keyset cursor: return documents with id > cursor" default(0)
 // @Param limit query int false "Limit"
+// @Param doc_type query string false "Filter by doc_type"
+// @Param created_after query int false "Filter to documents created after this unix timestamp"
+// @Param title_prefix query string false "Filter to titles starting with this prefix"
 // @Success 200 {object} map[string]interface{}
 // @Router /documents [get]
 func (h *Handler) ListDocuments(c *fiber.Ctx) error {
-	skip, _ := strconv.Atoi(c.Query("skip", "0"))
+	cursor, _ := strconv.ParseInt(c.Query("cursor", "0"), 10, 64)
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 
 	if limit > 100 {
 		limit = 100
 	}
 
-	milvusDocs, total, err := h.milvusClient.ListDocuments(limit, skip)
+	createdAfter, _ := strconv.ParseInt(c.Query("created_after", "0"), 10, 64)
+	filter := vector.ListFilter{
+		DocType:      c.Query("doc_type"),
+		CreatedAfter: createdAfter,
+		TitlePrefix:  c.Query("title_prefix"),
+	}
+
+	milvusDocs, nextCursor, total, err := h.store.ListDocuments(cursor, limit, filter, tenantID(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to retrieve documents: %v", err),
-		})
+		return err
 	}
 
 	var documents []models.Document
@@ -190,12 +412,17 @@ func (h *Handler) ListDocuments(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"documents": documents,
-		"total":     total,
-		"skip":      skip,
-		"limit":     limit,
-	})
+	body := fiber.Map{
+		"total":       total,
+		"cursor":      cursor,
+		"next_cursor": nextCursor,
+		"limit":       limit,
+		"_embedded": fiber.Map{
+			"documents": documents,
+		},
+	}
+
+	return hal.SendHAL(c, fiber.StatusOK, body, hal.CursorPaginationLinks("/api/v1/documents", cursor, limit, nextCursor))
 }
 
 // UpdateDocument godoc
@@ -222,15 +449,11 @@ func (h *Handler) UpdateDocument(c *fiber.Ctx) error {
 func (h *Handler) DeleteDocument(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid document ID",
-		})
+		return errs.New(errs.CodeValidation, "invalid document ID")
 	}
 
-	if err := h.milvusClient.DeleteDocument(int64(id)); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to delete document: %v", err),
-		})
+	if err := h.store.Delete(int64(id), tenantID(c)); err != nil {
+		return err
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
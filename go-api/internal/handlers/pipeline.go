@@ -1,11 +1,23 @@
 package handlers
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/kavishankarks/document-hub/go-api/internal/models"
-	"github.com/kavishankarks/document-hub/go-api/internal/pipeline"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/pipeline"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/utils/hal"
 )
 
+// eventStreamHeartbeat is how often StreamEvents sends an SSE comment to
+// keep idle proxies/load balancers from closing the connection.
+const eventStreamHeartbeat = 15 * time.Second
+
 // PipelineHandler handles pipeline-related requests
 type PipelineHandler struct {
 	orchestrator *pipeline.Orchestrator
@@ -33,20 +45,16 @@ func (h *PipelineHandler) StartPipeline(c *fiber.Ctx) error {
 	var req models.StartPipelineRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return errs.New(errs.CodeValidation, "invalid request body")
 	}
 
 	// Start the pipeline
 	pipelineRun, err := h.orchestrator.StartPipeline(&req.Curriculum, req.Config)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(pipelineRun)
+	return hal.SendHAL(c, fiber.StatusCreated, fiber.Map{"pipeline_run": pipelineRun}, hal.PipelineLinks(pipelineRun.ID))
 }
 
 // GetPipelineStatus gets the status of a pipeline run
@@ -61,19 +69,15 @@ func (h *PipelineHandler) StartPipeline(c *fiber.Ctx) error {
 func (h *PipelineHandler) GetPipelineStatus(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid pipeline ID",
-		})
+		return errs.New(errs.CodeValidation, "invalid pipeline ID")
 	}
 
 	status, err := h.orchestrator.GetPipelineStatus(uint(id))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	return c.JSON(status)
+	return hal.SendHAL(c, fiber.StatusOK, fiber.Map{"status": status}, hal.PipelineLinks(uint(id)))
 }
 
 // GetPipelineResults gets the results of a completed pipeline run
@@ -88,19 +92,15 @@ func (h *PipelineHandler) GetPipelineStatus(c *fiber.Ctx) error {
 func (h *PipelineHandler) GetPipelineResults(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid pipeline ID",
-		})
+		return errs.New(errs.CodeValidation, "invalid pipeline ID")
 	}
 
 	results, err := h.orchestrator.GetPipelineResults(uint(id))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	return c.JSON(results)
+	return hal.SendHAL(c, fiber.StatusOK, fiber.Map{"results": results}, hal.PipelineLinks(uint(id)))
 }
 
 // CancelPipeline cancels a running pipeline
@@ -116,20 +116,67 @@ func (h *PipelineHandler) GetPipelineResults(c *fiber.Ctx) error {
 func (h *PipelineHandler) CancelPipeline(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid pipeline ID",
-		})
+		return errs.New(errs.CodeValidation, "invalid pipeline ID")
 	}
 
 	if err := h.orchestrator.CancelPipeline(uint(id)); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	return c.JSON(fiber.Map{
-		"message": "Pipeline cancelled successfully",
-	})
+	return hal.SendHAL(c, fiber.StatusOK, fiber.Map{"message": "Pipeline cancelled successfully"}, hal.PipelineLinks(uint(id)))
+}
+
+// ResumePipeline re-enqueues outstanding work for a pending or processing
+// pipeline run, picking back up at each topic's checkpointed stage instead
+// of redoing completed ones. Only applies to durable (job-queue-backed)
+// runs; RecoverIncompleteRuns already does this for every such run on API
+// boot, so this is for resuming one specific run without a restart (e.g.
+// after confirming via the job list that its worker died).
+// @Summary Resume a pipeline run
+// @Description Re-enqueues outstanding work for a pending/processing pipeline run from its last checkpoint
+// @Tags pipeline
+// @Produce json
+// @Param id path int true "Pipeline Run ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/pipeline/{id}/resume [post]
+func (h *PipelineHandler) ResumePipeline(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return errs.New(errs.CodeValidation, "invalid pipeline ID")
+	}
+
+	if err := h.orchestrator.ResumePipeline(uint(id)); err != nil {
+		return err
+	}
+
+	return hal.SendHAL(c, fiber.StatusOK, fiber.Map{"message": "Pipeline resumed"}, hal.PipelineLinks(uint(id)))
+}
+
+// RetryFailedTopics re-runs just the dead-lettered topics of an in-memory
+// pipeline run (see pipeline.Orchestrator.RetryFailedTopics), without
+// re-running the topics that already completed.
+// @Summary Retry a pipeline run's dead-lettered topics
+// @Description Re-enqueues only the topics marked dead_letter after exhausting their retries, leaving completed topics untouched
+// @Tags pipeline
+// @Produce json
+// @Param id path int true "Pipeline Run ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/pipeline/{id}/retry [post]
+func (h *PipelineHandler) RetryFailedTopics(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return errs.New(errs.CodeValidation, "invalid pipeline ID")
+	}
+
+	if err := h.orchestrator.RetryFailedTopics(uint(id)); err != nil {
+		return err
+	}
+
+	return hal.SendHAL(c, fiber.StatusOK, fiber.Map{"message": "Retrying dead-lettered topics"}, hal.PipelineLinks(uint(id)))
 }
 
 // ListPipelines lists all pipeline runs with pagination
@@ -154,15 +201,179 @@ func (h *PipelineHandler) ListPipelines(c *fiber.Ctx) error {
 
 	pipelineRuns, total, err := h.orchestrator.ListPipelines(limit, skip)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch pipeline runs",
-		})
+		return err
+	}
+
+	body := fiber.Map{
+		"total": total,
+		"skip":  skip,
+		"limit": limit,
+		"_embedded": fiber.Map{
+			"pipeline_runs": pipelineRuns,
+		},
 	}
 
-	return c.JSON(fiber.Map{
-		"total":   total,
-		"skip":    skip,
-		"limit":   limit,
-		"results": pipelineRuns,
+	return hal.SendHAL(c, fiber.StatusOK, body, hal.PaginationLinks("/api/v1/pipelines", skip, limit, total))
+}
+
+// StreamEvents streams a pipeline run's progress as Server-Sent Events, so
+// clients don't have to poll GetPipelineStatus. A client reconnecting after
+// a dropped connection can send the Last-Event-ID header (set to the id of
+// the last event it saw) to replay whatever it missed from the orchestrator's
+// backlog instead of losing history.
+// @Summary Stream pipeline progress
+// @Description Streams stage_started/stage_progress/chunk_embedded/document_persisted/stage_completed/pipeline_completed/pipeline_failed events over SSE
+// @Tags pipeline
+// @Produce text/event-stream
+// @Param id path int true "Pipeline Run ID"
+// @Param Last-Event-ID header string false "Resume after this event ID"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 400,404 {object} map[string]string
+// @Router /api/v1/pipeline/{id}/events [get]
+func (h *PipelineHandler) StreamEvents(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return errs.New(errs.CodeValidation, "invalid pipeline ID")
+	}
+
+	if _, err := h.orchestrator.GetPipelineStatus(uint(id)); err != nil {
+		return err
+	}
+
+	var lastEventID uint64
+	if v := c.Get("Last-Event-ID"); v != "" {
+		lastEventID, _ = strconv.ParseUint(v, 10, 64)
+	}
+
+	events, backlog, unsubscribe := h.orchestrator.Events(uint(id), lastEventID)
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		writeEvent := func(ev pipeline.Event) bool {
+			data, _ := json.Marshal(ev)
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+			return w.Flush() == nil
+		}
+
+		for _, ev := range backlog {
+			if !writeEvent(ev) {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(eventStreamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				if !writeEvent(ev) {
+					return
+				}
+				if ev.Type == pipeline.EventPipelineCompleted || ev.Type == pipeline.EventPipelineFailed {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprintf(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				if w.Flush() != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+// StreamLogs returns a pipeline run's structured log lines (stage, topic,
+// timestamp, level, message; see pipeline.LineWriter). Without ?follow=1 it
+// returns the run's current log snapshot as JSON; with it, new lines are
+// streamed over SSE as the run produces them, same reconnect-friendly shape
+// as StreamEvents (just without Last-Event-ID resume, since log lines don't
+// need replay the way state-transition events do).
+// @Summary Stream or fetch pipeline logs
+// @Description Returns a pipeline run's structured log lines; pass follow=1 to stream new lines over SSE as they're produced
+// @Tags pipeline
+// @Produce json
+// @Produce text/event-stream
+// @Param id path int true "Pipeline Run ID"
+// @Param follow query bool false "Stream new lines over SSE instead of returning a snapshot"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,404 {object} map[string]string
+// @Router /api/v1/pipeline/{id}/logs [get]
+func (h *PipelineHandler) StreamLogs(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return errs.New(errs.CodeValidation, "invalid pipeline ID")
+	}
+
+	if !c.QueryBool("follow", false) {
+		lines, err := h.orchestrator.PipelineLogs(uint(id))
+		if err != nil {
+			return err
+		}
+		return hal.SendHAL(c, fiber.StatusOK, fiber.Map{"logs": lines}, hal.PipelineLinks(uint(id)))
+	}
+
+	newLines, backlog, unsubscribe := h.orchestrator.SubscribeLogs(uint(id))
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		writeLine := func(line pipeline.LogLine) bool {
+			data, _ := json.Marshal(line)
+			fmt.Fprintf(w, "event: log\ndata: %s\n\n", data)
+			return w.Flush() == nil
+		}
+
+		for _, line := range backlog {
+			if !writeLine(line) {
+				return
+			}
+		}
+
+		if newLines == nil {
+			// The run already finished; backlog above was its full flushed
+			// history, so there's nothing left to stream.
+			return
+		}
+
+		heartbeat := time.NewTicker(eventStreamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case line, ok := <-newLines:
+				if !ok {
+					return
+				}
+				if !writeLine(line) {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprintf(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				if w.Flush() != nil {
+					return
+				}
+			}
+		}
 	})
+
+	return nil
 }
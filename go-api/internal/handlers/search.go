@@ -3,18 +3,47 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/kavishankarks/document-hub/go-api/internal/models"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/vector"
 )
 
+// defaultRRFK and defaultRerankCandidates are the fallbacks for
+// HybridSearchOptions.RRFK and .RerankCandidates when a caller leaves them
+// unset (zero value). defaultDenseWeight/defaultSparseWeight are the
+// fallbacks for DenseWeight/SparseWeight.
+const (
+	defaultRRFK             = 60
+	defaultRerankCandidates = 50
+	defaultDenseWeight      = 1.0
+	defaultSparseWeight     = 1.0
+)
+
+// HybridSearchOptions tunes the RRF fusion and optional reranking stage used
+// by Handler.hybridSearch. Zero values fall back to the package defaults.
+type HybridSearchOptions struct {
+	RRFK             int     // k in score(d) = Σ weight_i/(k + rank_i(d)); 0 uses defaultRRFK
+	DenseWeight      float64 // weight applied to the dense leg's RRF contribution; 0 uses defaultDenseWeight
+	SparseWeight     float64 // weight applied to the sparse leg's RRF contribution; 0 uses defaultSparseWeight
+	Rerank           bool    // gated separately by RERANKER_URL being configured
+	RerankCandidates int     // how many fused results to send to the reranker; 0 uses defaultRerankCandidates
+}
+
 // Search godoc
 // @Summary Search documents
 // @Tags search
 // @Param q query string true "Search query"
-// @Param type query string false "Search type: semantic" default(semantic)
+// @Param type query string false "Search type: fulltext, semantic, hybrid, documents" default(semantic)
 // @Param limit query int false "Result limit" default(10)
 // @Param min_score query float64 false "Minimum score threshold (0.0-1.0)" default(0.3)
+// @Param rrf_k query int false "RRF k constant for hybrid search" default(60)
+// @Param dense_weight query number false "Weight applied to the dense leg of hybrid RRF fusion" default(1.0)
+// @Param sparse_weight query number false "Weight applied to the sparse leg of hybrid RRF fusion" default(1.0)
+// @Param rerank query bool false "Rerank semantic or hybrid results with the configured cross-encoder" default(false)
+// @Param rerank_candidates query int false "Number of top candidates to send to the reranker before truncating to limit" default(50)
 // @Success 200 {object} map[string]interface{}
 // @Router /search [get]
 func (h *Handler) Search(c *fiber.Ctx) error {
@@ -40,19 +69,35 @@ func (h *Handler) Search(c *fiber.Ctx) error {
 		minScore = 1
 	}
 
+	opts := HybridSearchOptions{
+		RRFK:             c.QueryInt("rrf_k", defaultRRFK),
+		DenseWeight:      c.QueryFloat("dense_weight", defaultDenseWeight),
+		SparseWeight:     c.QueryFloat("sparse_weight", defaultSparseWeight),
+		Rerank:           c.QueryBool("rerank", false),
+		RerankCandidates: c.QueryInt("rerank_candidates", defaultRerankCandidates),
+	}
+
 	var results []models.SearchResult
 
 	switch searchType {
 	case "fulltext":
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Full-text search is not supported with Milvus storage",
+		results = h.fulltextSearch(query, limit, minScore, tenantID(c))
+	case "semantic":
+		grouped := h.semanticSearch(query, limit, minScore, tenantID(c), opts.Rerank, opts.RerankCandidates)
+		return c.JSON(fiber.Map{
+			"query":       query,
+			"search_type": searchType,
+			"min_score":   minScore,
+			"results":     grouped,
+			"count":       len(grouped),
 		})
-	case "semantic", "hybrid":
-		// Hybrid is currently same as semantic since we don't have full-text
-		results = h.semanticSearch(query, limit, minScore)
+	case "hybrid":
+		results = h.hybridSearch(query, limit, minScore, opts, tenantID(c))
+	case "documents":
+		results = h.documentSearch(query, limit, minScore, tenantID(c))
 	default:
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid search type. Use 'semantic'",
+			"error": "Invalid search type. Use 'fulltext', 'semantic', 'hybrid', or 'documents'",
 		})
 	}
 
@@ -65,64 +110,452 @@ func (h *Handler) Search(c *fiber.Ctx) error {
 	})
 }
 
-// semanticSearch performs vector similarity search
-func (h *Handler) semanticSearch(query string, limit int, minScore float64) []models.SearchResult {
+// fulltextSearch runs a lexical-only query via vector.Store.FullTextSearch
+// (BM25/tsvector-style, see the interface doc), skipping embedding entirely.
+// Unlike semanticSearch/hybridSearch, this isn't tenant-scoped: neither
+// backend's full-text index partitions by tenant (see the Store interface).
+func (h *Handler) fulltextSearch(query string, limit int, minScore float64, tenant string) []models.SearchResult {
+	vectorResults, err := h.store.FullTextSearch(query, limit)
+	if err != nil {
+		fmt.Printf("Full-text search error: %v\n", err)
+		return []models.SearchResult{}
+	}
+
+	filtered := make([]vector.SearchResult, 0, len(vectorResults))
+	for _, res := range vectorResults {
+		res.Score = normalizeLexicalScore(res.Score)
+		if float64(res.Score) < minScore {
+			continue
+		}
+		filtered = append(filtered, res)
+	}
+
+	return h.hydrateResults(filtered, tenant)
+}
+
+// normalizeLexicalScore maps an unbounded, non-negative BM25/ts_rank score
+// (see vector.Store.FullTextSearch) onto [0,1) so it can be compared against
+// the same min_score threshold dense search uses, which is documented and
+// defaulted as a 0-1 similarity. Dense scores are already in that range;
+// lexical scores aren't, so applying min_score to them directly either drops
+// nearly every result (weak matches) or lets everything through uncapped
+// (strong matches). score/(1+score) is monotonic in score, so ranking is
+// unaffected - only the threshold comparison and the score reported to
+// callers change.
+func normalizeLexicalScore(score float32) float32 {
+	if score <= 0 {
+		return 0
+	}
+	return score / (1 + score)
+}
+
+// semanticSearch performs vector similarity search, scoped to tenant,
+// grouping hits by document so a document with multiple matching chunks
+// appears once with every matching chunk attached (see groupResultsByDocument)
+// instead of once per chunk. When rerank is true and a reranker is
+// configured, it fetches up to rerankCandidates chunks instead of just limit,
+// re-scores them with the cross-encoder, and truncates to limit afterward -
+// the same widen-then-truncate shape hybridSearch uses. If the reranker
+// isn't configured or errors, results fall back to plain dense order
+// unchanged (see applyRerank), so rerank stays opt-in without breaking
+// existing callers.
+func (h *Handler) semanticSearch(query string, limit int, minScore float64, tenant string, rerank bool, rerankCandidates int) []models.GroupedSearchResult {
 	// Get embedding for the query
 	embeddings, err := h.embeddingClient.GetEmbeddings([]string{query})
 	if err != nil {
-		return []models.SearchResult{}
+		return []models.GroupedSearchResult{}
 	}
 
 	if len(embeddings) == 0 {
+		return []models.GroupedSearchResult{}
+	}
+
+	if rerankCandidates <= 0 {
+		rerankCandidates = defaultRerankCandidates
+	}
+	poolSize := limit
+	if rerank && rerankCandidates > poolSize {
+		poolSize = rerankCandidates
+	}
+
+	vectorResults, err := h.store.Search(embeddings[0], poolSize, minScore, tenantSearchOpts(tenant)...)
+	if err != nil {
+		fmt.Printf("Vector search error: %v\n", err)
+		return []models.GroupedSearchResult{}
+	}
+
+	if rerank && h.reranker != nil && len(vectorResults) > 0 {
+		if len(vectorResults) > rerankCandidates {
+			vectorResults = vectorResults[:rerankCandidates]
+		}
+		vectorResults = h.applyRerank(query, vectorResults)
+	}
+
+	if len(vectorResults) > limit {
+		vectorResults = vectorResults[:limit]
+	}
+
+	return h.groupResultsByDocument(vectorResults, tenant)
+}
+
+// groupResultsByDocument batch-fetches every distinct document referenced by
+// vectorResults (via vector.Store.GetDocumentsByIDs, a single round-trip
+// rather than one GetDocument call per chunk) and folds each chunk hit into
+// its document's Chunks slice. Chunks within a document are sorted
+// best-score-first, and documents are ordered by their best chunk's score.
+func (h *Handler) groupResultsByDocument(vectorResults []vector.SearchResult, tenant string) []models.GroupedSearchResult {
+	if len(vectorResults) == 0 {
+		return []models.GroupedSearchResult{}
+	}
+
+	var orderedIDs []int64
+	seen := make(map[int64]bool, len(vectorResults))
+	for _, res := range vectorResults {
+		if !seen[res.DocumentID] {
+			seen[res.DocumentID] = true
+			orderedIDs = append(orderedIDs, res.DocumentID)
+		}
+	}
+
+	docs, err := h.store.GetDocumentsByIDs(orderedIDs, tenant)
+	if err != nil {
+		fmt.Printf("Warning: Failed to batch-get documents: %v\n", err)
+		return []models.GroupedSearchResult{}
+	}
+
+	grouped := make(map[int64]*models.GroupedSearchResult, len(docs))
+	for _, res := range vectorResults {
+		doc, ok := docs[res.DocumentID]
+		if !ok {
+			fmt.Printf("Warning: document %d missing from batch fetch\n", res.DocumentID)
+			continue
+		}
+
+		entry, ok := grouped[res.DocumentID]
+		if !ok {
+			var metadata map[string]interface{}
+			json.Unmarshal([]byte(doc.Metadata), &metadata)
+
+			entry = &models.GroupedSearchResult{
+				Document: models.Document{
+					ID:        uint(doc.ID),
+					Title:     doc.Title,
+					Content:   doc.Content,
+					SourceURL: doc.SourceURL,
+					DocType:   doc.DocType,
+					Metadata:  metadata,
+				},
+			}
+			grouped[res.DocumentID] = entry
+		}
+
+		entry.Chunks = append(entry.Chunks, models.ChunkHit{
+			ChunkIndex: res.ChunkIndex,
+			Score:      float64(res.Score),
+			Snippet:    res.ChunkText,
+		})
+	}
+
+	results := make([]models.GroupedSearchResult, 0, len(grouped))
+	for _, docID := range orderedIDs {
+		entry, ok := grouped[docID]
+		if !ok {
+			continue
+		}
+		sort.Slice(entry.Chunks, func(i, j int) bool {
+			return entry.Chunks[i].Score > entry.Chunks[j].Score
+		})
+		results = append(results, *entry)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Chunks[0].Score > results[j].Chunks[0].Score
+	})
+
+	return results
+}
+
+// documentSearch runs the coarse half of coarse-to-fine RAG: a dense search
+// over document-level embeddings (see vector.Store.SearchDocuments) instead
+// of chunks, so callers can narrow to a handful of relevant documents before
+// searching their chunks (e.g. via semanticSearch with WithDocumentIDs).
+func (h *Handler) documentSearch(query string, limit int, minScore float64, tenant string) []models.SearchResult {
+	embeddings, err := h.embeddingClient.GetEmbeddings([]string{query})
+	if err != nil || len(embeddings) == 0 {
+		return []models.SearchResult{}
+	}
+
+	docResults, err := h.store.SearchDocuments(embeddings[0], limit, tenantSearchOpts(tenant)...)
+	if err != nil {
+		fmt.Printf("Document search error: %v\n", err)
 		return []models.SearchResult{}
 	}
 
-	// Search in Milvus
-	milvusResults, err := h.milvusClient.Search(embeddings[0], limit, minScore)
+	results := make([]models.SearchResult, 0, len(docResults))
+	for _, dr := range docResults {
+		if float64(dr.Score) < minScore {
+			continue
+		}
+
+		var metadata map[string]interface{}
+		json.Unmarshal([]byte(dr.Metadata), &metadata)
+
+		results = append(results, models.SearchResult{
+			Document: models.Document{
+				ID:        uint(dr.ID),
+				Title:     dr.Title,
+				Content:   dr.Content,
+				SourceURL: dr.SourceURL,
+				DocType:   dr.DocType,
+				Metadata:  metadata,
+			},
+			Score: float64(dr.Score),
+		})
+	}
+
+	return results
+}
+
+// hybridSearch combines dense (vector) and sparse (full-text) retrieval with
+// weighted Reciprocal Rank Fusion, then optionally reranks the fused
+// candidates with a cross-encoder before truncating to limit. The two legs
+// run concurrently since neither depends on the other's result.
+func (h *Handler) hybridSearch(query string, limit int, minScore float64, opts HybridSearchOptions, tenant string) []models.SearchResult {
+	rrfK := opts.RRFK
+	if rrfK <= 0 {
+		rrfK = defaultRRFK
+	}
+	denseWeight := opts.DenseWeight
+	if denseWeight <= 0 {
+		denseWeight = defaultDenseWeight
+	}
+	sparseWeight := opts.SparseWeight
+	if sparseWeight <= 0 {
+		sparseWeight = defaultSparseWeight
+	}
+	rerankCandidates := opts.RerankCandidates
+	if rerankCandidates <= 0 {
+		rerankCandidates = defaultRerankCandidates
+	}
+
+	// When reranking, fetch a wider candidate pool than limit so the
+	// cross-encoder has enough to re-order before truncation.
+	poolSize := limit
+	if opts.Rerank && rerankCandidates > poolSize {
+		poolSize = rerankCandidates
+	}
+
+	var dense, sparse []vector.SearchResult
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		embeddings, err := h.embeddingClient.GetEmbeddings([]string{query})
+		if err != nil {
+			fmt.Printf("Failed to embed query for hybrid search: %v\n", err)
+			return
+		}
+		if len(embeddings) == 0 {
+			return
+		}
+		dense, err = h.store.Search(embeddings[0], poolSize, minScore, tenantSearchOpts(tenant)...)
+		if err != nil {
+			fmt.Printf("Vector search error: %v\n", err)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// FullTextSearch isn't tenant-scoped (see vector.Store), so hybrid
+		// search across tenants is only as isolated as the dense leg of the
+		// fusion.
+		results, err := h.store.FullTextSearch(query, poolSize)
+		if err != nil {
+			// Full-text search failed -- fall back to the dense ranking
+			// alone instead of failing the request.
+			return
+		}
+		sparse = h.rescoreSparse(query, results)
+	}()
+
+	wg.Wait()
+
+	fused := fuseRRF(dense, sparse, rrfK, denseWeight, sparseWeight)
+
+	if opts.Rerank && h.reranker != nil && len(fused) > 0 {
+		if len(fused) > rerankCandidates {
+			fused = fused[:rerankCandidates]
+		}
+		fused = h.applyRerank(query, fused)
+	}
+
+	if len(fused) > limit {
+		fused = fused[:limit]
+	}
+
+	return h.hydrateResults(fused, tenant)
+}
+
+// tenantSearchOpts builds the vector.SearchOption that scopes Search to a
+// single tenant's partition, or nil for the default/untenanted tenant so the
+// call behaves exactly as it did before tenancy existed.
+func tenantSearchOpts(tenant string) []vector.SearchOption {
+	if tenant == "" {
+		return nil
+	}
+	return []vector.SearchOption{vector.WithTenants([]string{tenant})}
+}
+
+// fuseRRF combines dense and sparse candidate lists with weighted
+// Reciprocal Rank Fusion: score(d) = Σ weight_i/(k + rank_i(d)) across
+// whichever lists contain d, using (document ID, chunk text) as the
+// identity of a candidate. The result is ordered by descending fused score.
+func fuseRRF(dense, sparse []vector.SearchResult, k int, denseWeight, sparseWeight float64) []vector.SearchResult {
+	type candidate struct {
+		result vector.SearchResult
+		score  float64
+	}
+
+	byKey := make(map[string]*candidate, len(dense)+len(sparse))
+
+	add := func(list []vector.SearchResult, weight float64) {
+		for rank, res := range list {
+			key := fmt.Sprintf("%d:%s", res.DocumentID, res.ChunkText)
+			c, ok := byKey[key]
+			if !ok {
+				c = &candidate{result: res}
+				byKey[key] = c
+			}
+			c.score += weight / float64(k+rank+1)
+		}
+	}
+	add(dense, denseWeight)
+	add(sparse, sparseWeight)
+
+	fused := make([]vector.SearchResult, 0, len(byKey))
+	for _, c := range byKey {
+		c.result.Score = float32(c.score)
+		fused = append(fused, c.result)
+	}
+
+	sort.Slice(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+
+	return fused
+}
+
+// rescoreSparse re-orders bm25Results (already ranked by the BM25 inverted
+// index, see vector.Store.FullTextSearch) using true SPLADE-style sparse
+// similarity when a sparseembed service is configured, instead of BM25's own
+// rank. BM25 gives a cheap, always-available recall pass; the sparse encoder
+// then re-scores just that small candidate pool against the query's sparse
+// vector, which is a much better relevance signal without needing every
+// chunk's sparse vector indexed ahead of time. Falls back to the BM25
+// ordering unchanged when sparseEmbedder is nil or the call fails.
+func (h *Handler) rescoreSparse(query string, bm25Results []vector.SearchResult) []vector.SearchResult {
+	if h.sparseEmbedder == nil || len(bm25Results) == 0 {
+		return bm25Results
+	}
+
+	texts := make([]string, len(bm25Results)+1)
+	texts[0] = query
+	for i, res := range bm25Results {
+		texts[i+1] = res.ChunkText
+	}
+
+	embeddings, err := h.sparseEmbedder.Embed(texts)
 	if err != nil {
-		fmt.Printf("Milvus search error: %v\n", err)
+		fmt.Printf("Sparse embedding error, falling back to BM25 order: %v\n", err)
+		return bm25Results
+	}
+
+	queryVec := embeddings[0]
+	rescored := make([]vector.SearchResult, len(bm25Results))
+	for i, res := range bm25Results {
+		res.Score = queryVec.Dot(embeddings[i+1])
+		rescored[i] = res
+	}
+
+	sort.Slice(rescored, func(i, j int) bool { return rescored[i].Score > rescored[j].Score })
+
+	return rescored
+}
+
+// applyRerank scores candidates against the query with the cross-encoder
+// reranker and reorders them by descending relevance. On a reranker error it
+// logs and returns the candidates unchanged, so an outage degrades to the
+// RRF-fused ranking instead of failing the search.
+func (h *Handler) applyRerank(query string, candidates []vector.SearchResult) []vector.SearchResult {
+	texts := make([]string, len(candidates))
+	for i, c := range candidates {
+		texts[i] = c.ChunkText
+	}
+
+	scores, err := h.reranker.Score(query, texts)
+	if err != nil {
+		fmt.Printf("Reranker error, falling back to RRF order: %v\n", err)
+		return candidates
+	}
+
+	for i := range candidates {
+		candidates[i].Score = scores[i]
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+
+	return candidates
+}
+
+// hydrateResults batch-fetches every distinct document referenced by
+// vectorResults (via vector.Store.GetDocumentsByIDs, a single round-trip
+// rather than one GetDocument call per result - see groupResultsByDocument,
+// which uses the same batch call) and assembles the models.SearchResult the
+// API returns, one per chunk hit.
+func (h *Handler) hydrateResults(vectorResults []vector.SearchResult, tenant string) []models.SearchResult {
+	if len(vectorResults) == 0 {
 		return []models.SearchResult{}
 	}
 
-	if len(milvusResults) == 0 {
+	var orderedIDs []int64
+	seen := make(map[int64]bool, len(vectorResults))
+	for _, res := range vectorResults {
+		if !seen[res.DocumentID] {
+			seen[res.DocumentID] = true
+			orderedIDs = append(orderedIDs, res.DocumentID)
+		}
+	}
+
+	docs, err := h.store.GetDocumentsByIDs(orderedIDs, tenant)
+	if err != nil {
+		fmt.Printf("Warning: Failed to batch-get documents: %v\n", err)
 		return []models.SearchResult{}
 	}
 
 	var results []models.SearchResult
 
-	// Fetch document details for each result
-	// Note: This could be optimized with a batch GetDocument if available
-	for _, res := range milvusResults {
-		milvusDoc, err := h.milvusClient.GetDocument(res.DocumentID)
-		if err != nil {
-			fmt.Printf("Warning: Failed to get document %d: %v\n", res.DocumentID, err)
+	for _, res := range vectorResults {
+		doc, ok := docs[res.DocumentID]
+		if !ok {
+			fmt.Printf("Warning: document %d missing from batch fetch\n", res.DocumentID)
 			continue
 		}
 
 		var metadata map[string]interface{}
-		json.Unmarshal([]byte(milvusDoc.Metadata), &metadata)
-
-		doc := models.Document{
-			ID:        uint(milvusDoc.ID),
-			Title:     milvusDoc.Title,
-			Content:   milvusDoc.Content,
-			SourceURL: milvusDoc.SourceURL,
-			DocType:   milvusDoc.DocType,
-			Metadata:  metadata,
-		}
+		json.Unmarshal([]byte(doc.Metadata), &metadata)
 
 		results = append(results, models.SearchResult{
-			Document: doc,
-			Score:    float64(res.Score),
-			Snippet:  res.ChunkText,
+			Document: models.Document{
+				ID:        uint(doc.ID),
+				Title:     doc.Title,
+				Content:   doc.Content,
+				SourceURL: doc.SourceURL,
+				DocType:   doc.DocType,
+				Metadata:  metadata,
+			},
+			Score:   float64(res.Score),
+			Snippet: res.ChunkText,
 		})
 	}
 
 	return results
 }
-
-// hybridSearch combines full-text and semantic search with weighted scores
-func (h *Handler) hybridSearch(query string, limit int, minScore float64) []models.SearchResult {
-	// Deprecated: Just alias to semantic search for now
-	return h.semanticSearch(query, limit, minScore)
-}
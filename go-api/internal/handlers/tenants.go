@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+)
+
+// CreateTenant godoc
+// @Summary Provision a tenant partition
+// @Description Creates the tenant's partition on the chunks and documents collections, if it doesn't already exist. Safe to call repeatedly. Requires the X-Admin-Key header.
+// @Tags tenants
+// @Param id path string true "Tenant ID"
+// @Param X-Admin-Key header string true "Admin credential"
+// @Success 204
+// @Failure 400,403,500 {object} map[string]string
+// @Router /tenants/{id} [put]
+func (h *Handler) CreateTenant(c *fiber.Ctx) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+
+	id := c.Params("id")
+	if id == "" {
+		return errs.New(errs.CodeValidation, "tenant id is required")
+	}
+
+	if err := h.store.EnsurePartition(id); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteTenant godoc
+// @Summary Offboard a tenant
+// @Description Drops the tenant's partition, and everything stored in it, from both collections. Requires the X-Admin-Key header.
+// @Tags tenants
+// @Param id path string true "Tenant ID"
+// @Param X-Admin-Key header string true "Admin credential"
+// @Success 204
+// @Failure 400,403,500 {object} map[string]string
+// @Router /tenants/{id} [delete]
+func (h *Handler) DeleteTenant(c *fiber.Ctx) error {
+	if err := h.requireAdmin(c); err != nil {
+		return err
+	}
+
+	id := c.Params("id")
+	if id == "" {
+		return errs.New(errs.CodeValidation, "tenant id is required")
+	}
+
+	if err := h.store.DropPartition(id); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
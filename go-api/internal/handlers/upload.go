@@ -1,27 +1,34 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/chunking"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/vector"
 )
 
 // UploadDocument godoc
 // @Summary Upload and process a document
-// @Description Uploads a file (PDF, Doc, Word, PPT, HTML), converts it to markdown, normalizes, chunks, embeds, and stores it.
+// @Description Uploads a file (PDF, Doc, Word, PPT, HTML) and returns a job id immediately; the file is durably stored, then converted to markdown, normalized, chunked, embedded, and indexed asynchronously. Poll GET /documents/jobs/:id for progress. Re-uploading the same bytes returns the existing job instead of reprocessing them.
 // @Tags documents
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "Document file"
-// @Success 201 {object} models.Document
+// @Param chunk_strategy formData string false "recursive (default), markdown_heading, semantic, token, late_chunking"
+// @Param chunk_size formData int false "Target chunk size (characters, or tokens for chunk_strategy=token)"
+// @Param chunk_overlap formData int false "Overlap between adjacent chunks"
+// @Success 202 {object} map[string]string
 // @Failure 400,500 {object} map[string]string
 // @Router /documents/upload [post]
 func (h *Handler) UploadDocument(c *fiber.Ctx) error {
-	// Get file from request
 	file, err := c.FormFile("file")
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -29,7 +36,16 @@ func (h *Handler) UploadDocument(c *fiber.Ctx) error {
 		})
 	}
 
-	// Open file
+	chunkStrategy := c.FormValue("chunk_strategy")
+	if _, ok := chunking.Get(chunkStrategy); !ok {
+		return errs.Newf(errs.CodeValidation, "unknown chunk_strategy %q", chunkStrategy)
+	}
+	if chunkStrategy == "" {
+		chunkStrategy = chunking.DefaultStrategy
+	}
+	chunkSize, _ := strconv.Atoi(c.FormValue("chunk_size"))
+	chunkOverlap, _ := strconv.Atoi(c.FormValue("chunk_overlap"))
+
 	f, err := file.Open()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -38,15 +54,65 @@ func (h *Handler) UploadDocument(c *fiber.Ctx) error {
 	}
 	defer f.Close()
 
-	// 1. Convert file to markdown
-	markdown, err := h.embeddingClient.ConvertDocument(file.Filename, f)
+	data, err := io.ReadAll(f)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to convert document: %v", err),
+			"error": fmt.Sprintf("Failed to read file: %v", err),
 		})
 	}
 
-	// 2. Normalize text
+	job, existed := h.uploadJobs.submit(tenantID(c), file.Filename, filepath.Ext(file.Filename), data, chunkStrategy, chunkSize, chunkOverlap)
+
+	status := fiber.StatusAccepted
+	if existed {
+		status = fiber.StatusOK
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"job_id": job.id,
+		"status": job.snapshot().Status,
+	})
+}
+
+// GetUploadJobStatus godoc
+// @Summary Get the status of an uploaded document's processing job
+// @Tags documents
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.UploadJobStatus
+// @Failure 404 {object} map[string]string
+// @Router /documents/jobs/{id} [get]
+func (h *Handler) GetUploadJobStatus(c *fiber.Ctx) error {
+	job, ok := h.uploadJobs.get(c.Params("id"))
+	if !ok || job.tenant != tenantID(c) {
+		return errs.New(errs.CodeNotFound, "upload job not found")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(job.snapshot())
+}
+
+// processUpload runs one upload job through converting, chunking, embedding,
+// and indexing, updating the job's stage as it goes. It's passed to
+// newUploadJobManager as the per-job worker function, running on one of the
+// manager's worker goroutines rather than the HTTP request goroutine.
+//
+// Each stage that has a side effect pushes a compensatingAction before
+// moving on, so a later stage's failure unwinds everything already done
+// instead of leaving an orphaned document behind - the same problem the
+// synchronous handler used to solve with a single ad-hoc h.store.Delete call
+// per failure site.
+func (h *Handler) processUpload(job *uploadJob) {
+	if _, err := h.objectStore.Put(job.contentHash, bytes.NewReader(job.data)); err != nil {
+		job.fail(fmt.Errorf("failed to persist upload: %w", err))
+		return
+	}
+
+	job.setStage("converting")
+	markdown, err := h.embeddingClient.ConvertDocument(job.filename, bytes.NewReader(job.data))
+	if err != nil {
+		job.fail(fmt.Errorf("failed to convert document: %w", err))
+		return
+	}
+
 	normalized, err := h.embeddingClient.NormalizeText(markdown, true)
 	if err != nil {
 		// Log warning but continue with original markdown if normalization fails
@@ -54,73 +120,93 @@ func (h *Handler) UploadDocument(c *fiber.Ctx) error {
 		normalized = markdown
 	}
 
-	// 3. Create document record in Milvus
 	metadata := map[string]interface{}{
 		"source":            "upload",
-		"original_filename": file.Filename,
+		"original_filename": job.filename,
 	}
 	metadataBytes, _ := json.Marshal(metadata)
 
 	milvusDoc := &vector.Document{
-		Title:     file.Filename,
+		Title:     job.filename,
 		Content:   "", // Store empty content to avoid size limits. Chunks contain the actual content.
 		SourceURL: "", // No source URL for uploaded files
-		DocType:   filepath.Ext(file.Filename),
+		DocType:   job.docType,
 		Metadata:  string(metadataBytes),
+		Embedding: h.embedDocumentSummary(job.filename, normalized),
 	}
 
-	docID, err := h.milvusClient.CreateDocument(milvusDoc)
+	job.setStage("indexing")
+	docID, err := h.store.CreateDocument(milvusDoc, job.tenant)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to create document record: %v", err),
-		})
+		job.fail(fmt.Errorf("failed to create document record: %w", err))
+		return
 	}
+	job.addCompensation(func() error { return h.store.Delete(docID, job.tenant) })
 
-	// 4. Chunk text
-	chunks, err := h.embeddingClient.ChunkText(normalized, 500)
+	job.setStage("chunking")
+	chunker, _ := chunking.Get(job.chunkStrategy) // validated in UploadDocument before the job was enqueued
+	docChunks, err := chunker.Chunk(normalized, chunking.Options{
+		MaxSize:       job.chunkSize,
+		Overlap:       job.chunkOverlap,
+		Embedder:      h.embeddingClient,
+		TokenEmbedder: h.embeddingClient,
+	})
 	if err != nil {
-		h.milvusClient.DeleteDocument(docID)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to chunk text: %v", err),
-		})
+		job.fail(fmt.Errorf("failed to chunk text: %w", err))
+		return
 	}
 
-	// 5. Generate embeddings
-	embeddings, err := h.embeddingClient.GetEmbeddings(chunks)
-	if err != nil {
-		h.milvusClient.DeleteDocument(docID)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to generate embeddings: %v", err),
-		})
+	// LateChunker already populates each Chunk.Embedding; every other
+	// strategy leaves it nil and needs its chunk texts embedded here.
+	job.setStage("embedding")
+	embeddings := make([][]float32, len(docChunks))
+	var toEmbed []string
+	var toEmbedIdx []int
+	for i, dc := range docChunks {
+		if dc.Embedding != nil {
+			embeddings[i] = dc.Embedding
+			continue
+		}
+		toEmbed = append(toEmbed, dc.Text)
+		toEmbedIdx = append(toEmbedIdx, i)
+	}
+	if len(toEmbed) > 0 {
+		embedded, err := h.embeddingClient.GetEmbeddings(toEmbed)
+		if err != nil {
+			job.fail(fmt.Errorf("failed to generate embeddings: %w", err))
+			return
+		}
+		for i, idx := range toEmbedIdx {
+			embeddings[idx] = embedded[i]
+		}
 	}
 
-	// 6. Store chunks
+	now := time.Now().Unix()
 	var milvusChunks []vector.Chunk
-	for i, chunk := range chunks {
+	for i, dc := range docChunks {
+		chunkMetadataBytes, _ := json.Marshal(fiber.Map{
+			"start":          dc.Start,
+			"end":            dc.End,
+			"heading_path":   dc.HeadingPath,
+			"chunk_strategy": job.chunkStrategy,
+		})
+
 		milvusChunks = append(milvusChunks, vector.Chunk{
 			DocumentID: docID,
 			ChunkIndex: int64(i),
-			ChunkText:  chunk,
+			ChunkText:  dc.Text,
 			Embedding:  embeddings[i],
+			Metadata:   string(chunkMetadataBytes),
+			DocType:    milvusDoc.DocType,
+			CreatedAt:  now,
 		})
 	}
 
-	// Store chunks in Milvus
-	if err := h.milvusClient.AddChunks(milvusChunks); err != nil {
-		h.milvusClient.DeleteDocument(docID)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("Failed to store chunks in vector DB: %v", err),
-		})
-	}
-
-	// Construct response
-	respDoc := models.Document{
-		ID:       uint(docID),
-		Title:    file.Filename,
-		Content:  normalized,
-		DocType:  filepath.Ext(file.Filename),
-		Metadata: metadata,
+	job.setStage("indexing")
+	if err := h.store.Upsert(milvusChunks, job.tenant); err != nil {
+		job.fail(fmt.Errorf("failed to store chunks in vector DB: %w", err))
+		return
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(respDoc)
+	job.complete(docID)
 }
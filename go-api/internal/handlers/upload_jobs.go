@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+)
+
+// uploadJobConcurrency bounds how many uploads are converted/chunked/
+// embedded/indexed at once, independent of how many requests arrive. Unlike
+// pipeline.WorkerPool this has no Postgres-backed queue behind it - jobs live
+// in process memory, so an API restart loses anything still in flight (see
+// models.UploadJobStatus).
+const uploadJobConcurrency = 4
+
+// uploadJobQueueSize bounds how many uploads can be queued ahead of the
+// workers before UploadDocument starts blocking the caller instead of
+// returning 202 immediately.
+const uploadJobQueueSize = 64
+
+// compensatingAction undoes one already-completed stage of an upload job, so
+// a later stage's failure can unwind everything before it instead of leaving
+// an orphaned document or chunk set behind.
+type compensatingAction func() error
+
+// uploadJob tracks one in-flight upload through converting, chunking,
+// embedding, and indexing.
+type uploadJob struct {
+	id            string
+	tenant        string
+	filename      string
+	docType       string
+	data          []byte
+	contentHash   string
+	chunkStrategy string
+	chunkSize     int
+	chunkOverlap  int
+
+	mu         sync.Mutex
+	status     string
+	stage      string
+	documentID int64
+	errMsg     string
+	createdAt  time.Time
+	updatedAt  time.Time
+
+	compensations []compensatingAction
+}
+
+func (j *uploadJob) snapshot() models.UploadJobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return models.UploadJobStatus{
+		ID:         j.id,
+		Status:     j.status,
+		Stage:      j.stage,
+		DocumentID: uint(j.documentID),
+		Error:      j.errMsg,
+		CreatedAt:  j.createdAt,
+		UpdatedAt:  j.updatedAt,
+	}
+}
+
+func (j *uploadJob) setStage(stage string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stage = stage
+	j.status = "processing"
+	j.updatedAt = time.Now()
+}
+
+func (j *uploadJob) addCompensation(action compensatingAction) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.compensations = append(j.compensations, action)
+}
+
+func (j *uploadJob) fail(err error) {
+	j.mu.Lock()
+	j.status = "failed"
+	j.errMsg = err.Error()
+	j.updatedAt = time.Now()
+	compensations := j.compensations
+	j.mu.Unlock()
+
+	for i := len(compensations) - 1; i >= 0; i-- {
+		if cerr := compensations[i](); cerr != nil {
+			fmt.Printf("Warning: upload job %s: compensating action failed: %v\n", j.id, cerr)
+		}
+	}
+}
+
+func (j *uploadJob) complete(documentID int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status = "completed"
+	j.stage = "indexing"
+	j.documentID = documentID
+	j.updatedAt = time.Now()
+}
+
+// uploadJobManager tracks in-flight and completed upload jobs and runs them
+// on a bounded pool of worker goroutines fed by a buffered channel, so a
+// burst of uploads queues up instead of spawning unbounded goroutines or
+// blocking the HTTP handler for the full convert/chunk/embed/index pipeline.
+type uploadJobManager struct {
+	process func(job *uploadJob)
+
+	mu     sync.RWMutex
+	jobs   map[string]*uploadJob
+	byHash map[string]string // tenant+"|"+contentHash -> job id, for idempotent re-uploads
+
+	queue chan *uploadJob
+}
+
+// newUploadJobManager creates a manager and starts its worker pool. process
+// is called once per job, on a worker goroutine.
+func newUploadJobManager(process func(job *uploadJob)) *uploadJobManager {
+	m := &uploadJobManager{
+		process: process,
+		jobs:    make(map[string]*uploadJob),
+		byHash:  make(map[string]string),
+		queue:   make(chan *uploadJob, uploadJobQueueSize),
+	}
+
+	for i := 0; i < uploadJobConcurrency; i++ {
+		go m.runWorker()
+	}
+
+	return m
+}
+
+func (m *uploadJobManager) runWorker() {
+	for job := range m.queue {
+		m.process(job)
+	}
+}
+
+// submit registers a new job for data under contentHash and enqueues it for
+// processing, unless a job already exists for that tenant+hash - in which
+// case the existing job is returned instead, making repeated uploads of the
+// same bytes idempotent rather than reprocessing and re-indexing them. The
+// hash key is scoped per tenant so two tenants uploading byte-identical
+// files don't collide onto the same job and document.
+func (m *uploadJobManager) submit(tenant, filename, docType string, data []byte, chunkStrategy string, chunkSize, chunkOverlap int) (*uploadJob, bool) {
+	hash := sha256.Sum256(data)
+	contentHash := hex.EncodeToString(hash[:])
+	hashKey := tenant + "|" + contentHash
+
+	m.mu.Lock()
+	if existingID, ok := m.byHash[hashKey]; ok {
+		existing := m.jobs[existingID]
+		m.mu.Unlock()
+		return existing, true
+	}
+
+	job := &uploadJob{
+		id:            newUploadJobID(),
+		tenant:        tenant,
+		filename:      filename,
+		docType:       docType,
+		data:          data,
+		contentHash:   contentHash,
+		chunkStrategy: chunkStrategy,
+		chunkSize:     chunkSize,
+		chunkOverlap:  chunkOverlap,
+		status:        "queued",
+		stage:         "converting",
+		createdAt:     time.Now(),
+		updatedAt:     time.Now(),
+	}
+	m.jobs[job.id] = job
+	m.byHash[hashKey] = job.id
+	m.mu.Unlock()
+
+	m.queue <- job
+	return job, false
+}
+
+func (m *uploadJobManager) get(id string) (*uploadJob, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// newUploadJobID generates an opaque job identifier: plain crypto/rand hex
+// rather than a UUID library.
+func newUploadJobID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand failing means the system RNG is broken; fall back to
+		// the current time rather than panicking on an upload request.
+		return "job-" + hex.EncodeToString([]byte(time.Now().String()))[:32]
+	}
+	return "job-" + hex.EncodeToString(b)
+}
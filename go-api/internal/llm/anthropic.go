@@ -0,0 +1,247 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AnthropicProvider implements LLMProvider for Anthropic's Messages API.
+type AnthropicProvider struct {
+	apiKey      string
+	client      *http.Client
+	model       string
+	temperature float64
+	maxTokens   int
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// anthropicDefaultMaxTokens is used when cfg.MaxTokens is unset, since
+// Anthropic's Messages API requires max_tokens on every request.
+const anthropicDefaultMaxTokens = 4096
+
+// NewAnthropicProvider creates a new Anthropic provider. cfg.Model overrides
+// ANTHROPIC_MODEL, and cfg.Temperature/cfg.MaxTokens are forwarded on every
+// request when set.
+func NewAnthropicProvider(cfg Config) (*AnthropicProvider, error) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable not set")
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = os.Getenv("ANTHROPIC_MODEL")
+	}
+	if model == "" {
+		model = "claude-3-5-sonnet-latest" // Default model
+	}
+
+	maxTokens := cfg.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = anthropicDefaultMaxTokens
+	}
+
+	return &AnthropicProvider{
+		apiKey:      apiKey,
+		client:      sharedHTTPClient,
+		model:       model,
+		temperature: cfg.Temperature,
+		maxTokens:   maxTokens,
+	}, nil
+}
+
+func (p *AnthropicProvider) newRequest(ctx context.Context, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// GenerateContent generates text content based on the prompt using the
+// Anthropic Messages API.
+func (p *AnthropicProvider) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	reqBody := anthropicRequest{
+		Model:       p.model,
+		Messages:    []anthropicMessage{{Role: "user", Content: prompt}},
+		MaxTokens:   p.maxTokens,
+		Temperature: p.temperature,
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := p.newRequest(ctx, jsonBody)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", classifyHTTPError("anthropic", resp, bodyBytes)
+	}
+
+	var anthropicResp anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&anthropicResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if anthropicResp.Error != nil {
+		return "", fmt.Errorf("anthropic api error: %s", anthropicResp.Error.Message)
+	}
+
+	var result string
+	for _, block := range anthropicResp.Content {
+		if block.Type == "text" {
+			result += block.Text
+		}
+	}
+
+	if result == "" {
+		return "", fmt.Errorf("no content generated")
+	}
+
+	return result, nil
+}
+
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// GenerateContentStream streams the generated content token-by-token using
+// Anthropic's `stream: true` Messages API variant.
+func (p *AnthropicProvider) GenerateContentStream(ctx context.Context, prompt string) (<-chan TokenChunk, error) {
+	reqBody := anthropicRequest{
+		Model:       p.model,
+		Messages:    []anthropicMessage{{Role: "user", Content: prompt}},
+		MaxTokens:   p.maxTokens,
+		Temperature: p.temperature,
+		Stream:      true,
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := p.newRequest(ctx, jsonBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, classifyHTTPError("anthropic", resp, bodyBytes)
+	}
+
+	ch := make(chan TokenChunk)
+
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+			var event anthropicStreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				ch <- TokenChunk{Err: fmt.Errorf("failed to decode stream event: %w", err)}
+				return
+			}
+
+			if event.Error != nil {
+				ch <- TokenChunk{Err: fmt.Errorf("anthropic api error: %s", event.Error.Message)}
+				return
+			}
+
+			switch event.Type {
+			case "content_block_delta":
+				if event.Delta.Text != "" {
+					ch <- TokenChunk{Text: event.Delta.Text}
+				}
+			case "message_delta":
+				if event.Delta.StopReason != "" {
+					ch <- TokenChunk{Done: true, FinishReason: event.Delta.StopReason}
+					return
+				}
+			case "message_stop":
+				ch <- TokenChunk{Done: true, FinishReason: "stop"}
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			ch <- TokenChunk{Err: fmt.Errorf("failed to read stream: %w", err)}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Close is a no-op for AnthropicProvider as it uses http.Client
+func (p *AnthropicProvider) Close() error {
+	return nil
+}
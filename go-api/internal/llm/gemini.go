@@ -6,6 +6,8 @@ import (
 	"os"
 
 	"github.com/google/generative-ai-go/genai"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
@@ -15,8 +17,19 @@ type GeminiProvider struct {
 	model  *genai.GenerativeModel
 }
 
-// NewGeminiProvider creates a new Gemini provider
-func NewGeminiProvider(ctx context.Context) (*GeminiProvider, error) {
+// geminiSafetyThresholds maps Config.SafetyThreshold's provider-agnostic
+// name to the genai threshold constant it selects.
+var geminiSafetyThresholds = map[string]genai.HarmBlockThreshold{
+	"none":             genai.HarmBlockNone,
+	"low_and_above":    genai.HarmBlockLowAndAbove,
+	"medium_and_above": genai.HarmBlockMediumAndAbove,
+	"only_high":        genai.HarmBlockOnlyHigh,
+}
+
+// NewGeminiProvider creates a new Gemini provider. cfg.Model, cfg.Temperature,
+// cfg.MaxTokens, and cfg.SafetyThreshold override the defaults below when
+// set; a zero Config reproduces the previous hardcoded behavior.
+func NewGeminiProvider(ctx context.Context, cfg Config) (*GeminiProvider, error) {
 	apiKey := os.Getenv("GEMINI_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("GEMINI_API_KEY environment variable not set")
@@ -27,18 +40,28 @@ func NewGeminiProvider(ctx context.Context) (*GeminiProvider, error) {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
 
-	model := client.GenerativeModel("gemini-3-pro-preview")
+	modelName := cfg.Model
+	if modelName == "" {
+		modelName = "gemini-3-pro-preview"
+	}
+	model := client.GenerativeModel(modelName)
+
+	if cfg.Temperature > 0 {
+		model.SetTemperature(float32(cfg.Temperature))
+	}
+	if cfg.MaxTokens > 0 {
+		model.SetMaxOutputTokens(int32(cfg.MaxTokens))
+	}
 
-	// Set default safety settings to be less restrictive for educational content
+	threshold, ok := geminiSafetyThresholds[cfg.SafetyThreshold]
+	if !ok {
+		// Default to less restrictive than Gemini's own default, since this
+		// provider is mainly used for educational content.
+		threshold = genai.HarmBlockMediumAndAbove
+	}
 	model.SafetySettings = []*genai.SafetySetting{
-		{
-			Category:  genai.HarmCategoryHarassment,
-			Threshold: genai.HarmBlockMediumAndAbove,
-		},
-		{
-			Category:  genai.HarmCategoryHateSpeech,
-			Threshold: genai.HarmBlockMediumAndAbove,
-		},
+		{Category: genai.HarmCategoryHarassment, Threshold: threshold},
+		{Category: genai.HarmCategoryHateSpeech, Threshold: threshold},
 	}
 
 	return &GeminiProvider{
@@ -47,6 +70,24 @@ func NewGeminiProvider(ctx context.Context) (*GeminiProvider, error) {
 	}, nil
 }
 
+// geminiSafetyBlockError reports whether resp was blocked by Gemini's safety
+// filter rather than genuinely producing no content, so callers can surface
+// errs.CodeLLMSafetyBlocked instead of a generic "no content generated".
+// Covers both ways Gemini signals a block: PromptFeedback.BlockReason (the
+// whole request was rejected before generating) and a candidate's
+// FinishReason (generation started but was cut off by safety).
+func geminiSafetyBlockError(resp *genai.GenerateContentResponse) *errs.Error {
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
+		return errs.Newf(errs.CodeLLMSafetyBlocked, "prompt blocked by safety filter: %s", resp.PromptFeedback.BlockReason)
+	}
+
+	if len(resp.Candidates) > 0 && resp.Candidates[0].FinishReason == genai.FinishReasonSafety {
+		return errs.New(errs.CodeLLMSafetyBlocked, "generation blocked by safety filter")
+	}
+
+	return nil
+}
+
 // GenerateContent generates text content based on the prompt
 func (p *GeminiProvider) GenerateContent(ctx context.Context, prompt string) (string, error) {
 	resp, err := p.model.GenerateContent(ctx, genai.Text(prompt))
@@ -54,6 +95,10 @@ func (p *GeminiProvider) GenerateContent(ctx context.Context, prompt string) (st
 		return "", fmt.Errorf("failed to generate content: %w", err)
 	}
 
+	if blockErr := geminiSafetyBlockError(resp); blockErr != nil {
+		return "", blockErr
+	}
+
 	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
 		return "", fmt.Errorf("no content generated")
 	}
@@ -68,6 +113,45 @@ func (p *GeminiProvider) GenerateContent(ctx context.Context, prompt string) (st
 	return result, nil
 }
 
+// GenerateContentStream streams the generated content token-by-token.
+func (p *GeminiProvider) GenerateContentStream(ctx context.Context, prompt string) (<-chan TokenChunk, error) {
+	iter := p.model.GenerateContentStream(ctx, genai.Text(prompt))
+	ch := make(chan TokenChunk)
+
+	go func() {
+		defer close(ch)
+
+		for {
+			resp, err := iter.Next()
+			if err == iterator.Done {
+				ch <- TokenChunk{Done: true, FinishReason: "stop"}
+				return
+			}
+			if err != nil {
+				ch <- TokenChunk{Err: fmt.Errorf("failed to stream content: %w", err)}
+				return
+			}
+
+			if blockErr := geminiSafetyBlockError(resp); blockErr != nil {
+				ch <- TokenChunk{Err: blockErr}
+				return
+			}
+
+			if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
+				continue
+			}
+
+			for _, part := range resp.Candidates[0].Content.Parts {
+				if txt, ok := part.(genai.Text); ok {
+					ch <- TokenChunk{Text: string(txt)}
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
 // Close closes the Gemini client
 func (p *GeminiProvider) Close() error {
 	return p.client.Close()
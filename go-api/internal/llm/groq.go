@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -8,18 +9,24 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 )
 
 // GroqProvider implements LLMProvider for Groq API
 type GroqProvider struct {
-	apiKey string
-	client *http.Client
-	model  string
+	apiKey      string
+	client      *http.Client
+	model       string
+	temperature float64
+	maxTokens   int
 }
 
 type groqRequest struct {
-	Model    string        `json:"model"`
-	Messages []groqMessage `json:"messages"`
+	Model       string        `json:"model"`
+	Messages    []groqMessage `json:"messages"`
+	Stream      bool          `json:"stream,omitempty"`
+	Temperature float64       `json:"temperature,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
 }
 
 type groqMessage struct {
@@ -38,22 +45,29 @@ type groqResponse struct {
 	} `json:"error,omitempty"`
 }
 
-// NewGroqProvider creates a new Groq provider
-func NewGroqProvider() (*GroqProvider, error) {
+// NewGroqProvider creates a new Groq provider. cfg.Model overrides
+// GROQ_MODEL, and cfg.Temperature/cfg.MaxTokens are forwarded on every
+// request when set.
+func NewGroqProvider(cfg Config) (*GroqProvider, error) {
 	apiKey := os.Getenv("GROQ_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("GROQ_API_KEY environment variable not set")
 	}
 
-	model := os.Getenv("GROQ_MODEL")
+	model := cfg.Model
+	if model == "" {
+		model = os.Getenv("GROQ_MODEL")
+	}
 	if model == "" {
 		model = "openai/gpt-oss-20b" // Default model
 	}
 
 	return &GroqProvider{
-		apiKey: apiKey,
-		client: &http.Client{},
-		model:  model,
+		apiKey:      apiKey,
+		client:      sharedHTTPClient,
+		model:       model,
+		temperature: cfg.Temperature,
+		maxTokens:   cfg.MaxTokens,
 	}, nil
 }
 
@@ -67,6 +81,8 @@ func (p *GroqProvider) GenerateContent(ctx context.Context, prompt string) (stri
 				Content: prompt,
 			},
 		},
+		Temperature: p.temperature,
+		MaxTokens:   p.maxTokens,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -90,7 +106,7 @@ func (p *GroqProvider) GenerateContent(ctx context.Context, prompt string) (stri
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("api returned status: %d, body: %s", resp.StatusCode, string(bodyBytes))
+		return "", classifyHTTPError("groq", resp, bodyBytes)
 	}
 
 	var groqResp groqResponse
@@ -109,6 +125,111 @@ func (p *GroqProvider) GenerateContent(ctx context.Context, prompt string) (stri
 	return groqResp.Choices[0].Message.Content, nil
 }
 
+type groqStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// GenerateContentStream streams the generated content token-by-token using
+// Groq's `stream: true` chat completions variant.
+func (p *GroqProvider) GenerateContentStream(ctx context.Context, prompt string) (<-chan TokenChunk, error) {
+	reqBody := groqRequest{
+		Model: p.model,
+		Messages: []groqMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		Stream:      true,
+		Temperature: p.temperature,
+		MaxTokens:   p.maxTokens,
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.groq.com/openai/v1/chat/completions", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, classifyHTTPError("groq", resp, bodyBytes)
+	}
+
+	ch := make(chan TokenChunk)
+
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				ch <- TokenChunk{Done: true, FinishReason: "stop"}
+				return
+			}
+
+			var chunk groqStreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				ch <- TokenChunk{Err: fmt.Errorf("failed to decode stream chunk: %w", err)}
+				return
+			}
+
+			if chunk.Error != nil {
+				ch <- TokenChunk{Err: fmt.Errorf("groq api error: %s", chunk.Error.Message)}
+				return
+			}
+
+			if len(chunk.Choices) == 0 {
+				continue
+			}
+
+			choice := chunk.Choices[0]
+			if choice.Delta.Content != "" {
+				ch <- TokenChunk{Text: choice.Delta.Content}
+			}
+			if choice.FinishReason != nil {
+				ch <- TokenChunk{Done: true, FinishReason: *choice.FinishReason}
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			ch <- TokenChunk{Err: fmt.Errorf("failed to read stream: %w", err)}
+		}
+	}()
+
+	return ch, nil
+}
+
 // Close is a no-op for GroqProvider as it uses http.Client
 func (p *GroqProvider) Close() error {
 	return nil
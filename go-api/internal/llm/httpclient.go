@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+)
+
+// sharedHTTPClient is used by every provider that talks to its API directly
+// over HTTP (Groq, OpenAI-compatible, Anthropic, Ollama), instead of each
+// constructing its own bare &http.Client{}. Its Transport pools and reuses
+// connections across requests and across providers, which matters here
+// since a single pipeline run or chat session can issue many generations in
+// quick succession.
+var sharedHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// classifyHTTPError turns a non-200 response into an *errs.Error, marking
+// 429/5xx retryable (rate limiting, upstream overload/restart) and
+// everything else (4xx other than 429, meaning our own request was bad)
+// terminal, the same split embedding_client.classifyHTTPError uses. A 429's
+// Retry-After header, if present, is parsed and attached so withRetry can
+// honor it instead of guessing its own backoff - Groq in particular sends
+// this on nearly every rate-limit response.
+func classifyHTTPError(provider string, resp *http.Response, body []byte) error {
+	message := fmt.Sprintf("%s api returned status %d", provider, resp.StatusCode)
+	cause := fmt.Errorf("%s", body)
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > 0 {
+			return errs.WrapRetryableAfter(errs.CodeLLMUpstream, message, cause, retryAfter)
+		}
+		return errs.WrapRetryable(errs.CodeLLMUpstream, message, cause)
+	}
+
+	return errs.Wrap(errs.CodeLLMUpstream, message, cause)
+}
+
+// parseRetryAfter parses an HTTP Retry-After header's delta-seconds form
+// (e.g. "Retry-After: 2"). The HTTP-date form isn't handled - none of these
+// providers send it - so an unparseable or empty header just falls back to
+// the retry policy's own backoff.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// retryPolicy is the exponential-backoff-with-jitter schedule withRetry
+// follows, mirroring the pipeline package's retry.go (duplicated rather than
+// shared, since pipeline already imports llm and a shared dependency would
+// cycle back).
+type retryPolicy struct {
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	maxRetries:     3,
+	initialBackoff: 500 * time.Millisecond,
+	maxBackoff:     10 * time.Second,
+}
+
+// withRetry calls fn, retrying per policy on an *errs.Error marked
+// Retryable. A terminal error (bad request, auth failure) returns
+// immediately instead of burning through retries pointlessly. An error's
+// RetryAfter, when set, overrides the computed backoff.
+func withRetry(ctx context.Context, policy retryPolicy, fn func() error) error {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		lastErr = fn()
+		if lastErr == nil {
+			return nil
+		}
+
+		e, ok := errs.As(lastErr)
+		if !ok || !e.Retryable {
+			return lastErr
+		}
+		if attempt >= policy.maxRetries {
+			return lastErr
+		}
+
+		wait := backoffWithJitter(policy, attempt)
+		if e.RetryAfter > 0 {
+			wait = e.RetryAfter
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoffWithJitter doubles policy.initialBackoff per attempt up to
+// policy.maxBackoff, then applies +/-50% jitter so a burst of concurrent
+// callers retrying the same upstream don't all land on the same instant.
+func backoffWithJitter(policy retryPolicy, attempt int) time.Duration {
+	backoff := policy.initialBackoff << attempt
+	if backoff > policy.maxBackoff || backoff <= 0 {
+		backoff = policy.maxBackoff
+	}
+
+	jitter := time.Duration(rand.Float64()*float64(backoff)) - backoff/2
+	wait := backoff + jitter
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// retryingProvider wraps an LLMProvider so every GenerateContent/
+// GenerateContentStream call goes through withRetry, honoring whatever
+// Retry-After/backoff the wrapped provider's errors carry. DefaultRegistry
+// wraps every built-in provider in one of these, so retry behavior lives in
+// a single place instead of being reimplemented per provider.
+type retryingProvider struct {
+	inner  LLMProvider
+	policy retryPolicy
+}
+
+// withRetries wraps provider with the default retry policy.
+func withRetries(provider LLMProvider) LLMProvider {
+	return &retryingProvider{inner: provider, policy: defaultRetryPolicy}
+}
+
+func (p *retryingProvider) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	var result string
+	err := withRetry(ctx, p.policy, func() error {
+		var err error
+		result, err = p.inner.GenerateContent(ctx, prompt)
+		return err
+	})
+	return result, err
+}
+
+// GenerateContentStream retries only the initial request: every provider's
+// implementation only returns an error synchronously, before it starts
+// streaming TokenChunks, so a mid-stream failure (TokenChunk.Err) is never
+// in scope here and isn't retried - the caller already has partial output
+// by then.
+func (p *retryingProvider) GenerateContentStream(ctx context.Context, prompt string) (<-chan TokenChunk, error) {
+	var ch <-chan TokenChunk
+	err := withRetry(ctx, p.policy, func() error {
+		var err error
+		ch, err = p.inner.GenerateContentStream(ctx, prompt)
+		return err
+	})
+	return ch, err
+}
+
+func (p *retryingProvider) Close() error {
+	return p.inner.Close()
+}
@@ -5,5 +5,16 @@ import "context"
 // LLMProvider defines the interface for LLM interactions
 type LLMProvider interface {
 	GenerateContent(ctx context.Context, prompt string) (string, error)
+	GenerateContentStream(ctx context.Context, prompt string) (<-chan TokenChunk, error)
 	Close() error
 }
+
+// TokenChunk is a single token/delta emitted while streaming a generation.
+// The final chunk on a stream has Done set to true and carries the stop
+// reason; the channel is closed immediately after it.
+type TokenChunk struct {
+	Text         string
+	Done         bool
+	FinishReason string
+	Err          error
+}
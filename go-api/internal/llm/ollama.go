@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// OllamaProvider implements LLMProvider against a local Ollama instance's
+// /api/generate endpoint.
+type OllamaProvider struct {
+	baseURL     string
+	client      *http.Client
+	model       string
+	temperature float64
+	maxTokens   int
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature,omitempty"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+type ollamaRequest struct {
+	Model   string        `json:"model"`
+	Prompt  string        `json:"prompt"`
+	Stream  bool          `json:"stream"`
+	Options ollamaOptions `json:"options,omitempty"`
+}
+
+type ollamaResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
+
+// NewOllamaProvider creates a new Ollama provider. OLLAMA_URL points it at a
+// non-default host (default http://localhost:11434); cfg.Model overrides
+// OLLAMA_MODEL.
+func NewOllamaProvider(cfg Config) (*OllamaProvider, error) {
+	baseURL := os.Getenv("OLLAMA_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = os.Getenv("OLLAMA_MODEL")
+	}
+	if model == "" {
+		model = "llama3.1" // Default model
+	}
+
+	return &OllamaProvider{
+		baseURL:     baseURL,
+		client:      sharedHTTPClient,
+		model:       model,
+		temperature: cfg.Temperature,
+		maxTokens:   cfg.MaxTokens,
+	}, nil
+}
+
+func (p *OllamaProvider) options() ollamaOptions {
+	return ollamaOptions{Temperature: p.temperature, NumPredict: p.maxTokens}
+}
+
+// GenerateContent generates text content based on the prompt using Ollama.
+func (p *OllamaProvider) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	reqBody := ollamaRequest{
+		Model:   p.model,
+		Prompt:  prompt,
+		Stream:  false,
+		Options: p.options(),
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/generate", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", classifyHTTPError("ollama", resp, bodyBytes)
+	}
+
+	var ollamaResp ollamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if ollamaResp.Error != "" {
+		return "", fmt.Errorf("ollama error: %s", ollamaResp.Error)
+	}
+
+	return ollamaResp.Response, nil
+}
+
+// GenerateContentStream streams the generated content token-by-token. Ollama
+// streams newline-delimited JSON objects rather than SSE `data:` frames.
+func (p *OllamaProvider) GenerateContentStream(ctx context.Context, prompt string) (<-chan TokenChunk, error) {
+	reqBody := ollamaRequest{
+		Model:   p.model,
+		Prompt:  prompt,
+		Stream:  true,
+		Options: p.options(),
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/generate", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, classifyHTTPError("ollama", resp, bodyBytes)
+	}
+
+	ch := make(chan TokenChunk)
+
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+
+			var chunk ollamaResponse
+			if err := json.Unmarshal(line, &chunk); err != nil {
+				ch <- TokenChunk{Err: fmt.Errorf("failed to decode stream chunk: %w", err)}
+				return
+			}
+
+			if chunk.Error != "" {
+				ch <- TokenChunk{Err: fmt.Errorf("ollama error: %s", chunk.Error)}
+				return
+			}
+
+			if chunk.Response != "" {
+				ch <- TokenChunk{Text: chunk.Response}
+			}
+			if chunk.Done {
+				ch <- TokenChunk{Done: true, FinishReason: "stop"}
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			ch <- TokenChunk{Err: fmt.Errorf("failed to read stream: %w", err)}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Close is a no-op for OllamaProvider as it uses http.Client
+func (p *OllamaProvider) Close() error {
+	return nil
+}
@@ -0,0 +1,276 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// OpenAIProvider implements LLMProvider for OpenAI's chat completions API,
+// and doubles as the provider for any OpenAI-compatible endpoint (see
+// NewLlamaCppProvider) since the request/response shapes are identical.
+type OpenAIProvider struct {
+	baseURL     string
+	apiKey      string
+	client      *http.Client
+	model       string
+	temperature float64
+	maxTokens   int
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Stream      bool            `json:"stream,omitempty"`
+	Temperature float64         `json:"temperature,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NewOpenAIProvider creates a new OpenAI provider. cfg.Model overrides
+// OPENAI_MODEL, OPENAI_BASE_URL overrides the default API host (e.g. for an
+// Azure/OpenAI-compatible proxy), and cfg.Temperature/cfg.MaxTokens are
+// forwarded on every request when set.
+func NewOpenAIProvider(cfg Config) (*OpenAIProvider, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
+	}
+
+	baseURL := os.Getenv("OPENAI_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = os.Getenv("OPENAI_MODEL")
+	}
+	if model == "" {
+		model = "gpt-4o-mini" // Default model
+	}
+
+	return newOpenAICompatibleProvider(baseURL, apiKey, model, cfg), nil
+}
+
+// NewLlamaCppProvider creates an OpenAIProvider pointed at a local
+// llama.cpp server's OpenAI-compatible /v1 endpoint (LLAMACPP_URL, default
+// http://localhost:8080/v1). Unlike OpenAI itself, no API key is required -
+// llama.cpp doesn't check one - so LLAMACPP_MODEL (default "local") is the
+// only thing callers typically need to set.
+func NewLlamaCppProvider(cfg Config) (*OpenAIProvider, error) {
+	baseURL := os.Getenv("LLAMACPP_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080/v1"
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = os.Getenv("LLAMACPP_MODEL")
+	}
+	if model == "" {
+		model = "local"
+	}
+
+	return newOpenAICompatibleProvider(baseURL, "", model, cfg), nil
+}
+
+// newOpenAICompatibleProvider builds the OpenAIProvider struct shared by
+// NewOpenAIProvider and NewLlamaCppProvider so the two constructors don't
+// duplicate the struct literal.
+func newOpenAICompatibleProvider(baseURL, apiKey, model string, cfg Config) *OpenAIProvider {
+	return &OpenAIProvider{
+		baseURL:     baseURL,
+		apiKey:      apiKey,
+		client:      sharedHTTPClient,
+		model:       model,
+		temperature: cfg.Temperature,
+		maxTokens:   cfg.MaxTokens,
+	}
+}
+
+func (p *OpenAIProvider) setAuth(req *http.Request) {
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+}
+
+// GenerateContent generates text content based on the prompt using the
+// OpenAI API.
+func (p *OpenAIProvider) GenerateContent(ctx context.Context, prompt string) (string, error) {
+	reqBody := openAIRequest{
+		Model: p.model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: prompt},
+		},
+		Temperature: p.temperature,
+		MaxTokens:   p.maxTokens,
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	p.setAuth(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", classifyHTTPError("openai", resp, bodyBytes)
+	}
+
+	var openAIResp openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&openAIResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if openAIResp.Error != nil {
+		return "", fmt.Errorf("openai api error: %s", openAIResp.Error.Message)
+	}
+
+	if len(openAIResp.Choices) == 0 {
+		return "", fmt.Errorf("no content generated")
+	}
+
+	return openAIResp.Choices[0].Message.Content, nil
+}
+
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// GenerateContentStream streams the generated content token-by-token using
+// OpenAI's `stream: true` chat completions variant.
+func (p *OpenAIProvider) GenerateContentStream(ctx context.Context, prompt string) (<-chan TokenChunk, error) {
+	reqBody := openAIRequest{
+		Model: p.model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: prompt},
+		},
+		Stream:      true,
+		Temperature: p.temperature,
+		MaxTokens:   p.maxTokens,
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	p.setAuth(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, classifyHTTPError("openai", resp, bodyBytes)
+	}
+
+	ch := make(chan TokenChunk)
+
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				ch <- TokenChunk{Done: true, FinishReason: "stop"}
+				return
+			}
+
+			var chunk openAIStreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				ch <- TokenChunk{Err: fmt.Errorf("failed to decode stream chunk: %w", err)}
+				return
+			}
+
+			if chunk.Error != nil {
+				ch <- TokenChunk{Err: fmt.Errorf("openai api error: %s", chunk.Error.Message)}
+				return
+			}
+
+			if len(chunk.Choices) == 0 {
+				continue
+			}
+
+			choice := chunk.Choices[0]
+			if choice.Delta.Content != "" {
+				ch <- TokenChunk{Text: choice.Delta.Content}
+			}
+			if choice.FinishReason != nil {
+				ch <- TokenChunk{Done: true, FinishReason: *choice.FinishReason}
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			ch <- TokenChunk{Err: fmt.Errorf("failed to read stream: %w", err)}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Close is a no-op for OpenAIProvider as it uses http.Client
+func (p *OpenAIProvider) Close() error {
+	return nil
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Config carries the provider-agnostic knobs callers can tune per request or
+// pipeline run instead of having them hardcoded into a provider constructor.
+// A zero value means "use the provider's own default".
+type Config struct {
+	Model           string  // overrides the provider's default model name
+	Temperature     float64 // sampling temperature; 0 means provider default
+	MaxTokens       int     // max output tokens; 0 means provider default
+	SafetyThreshold string  // provider-specific content-filter threshold name
+}
+
+// Factory builds an LLMProvider from a Config.
+type Factory func(ctx context.Context, cfg Config) (LLMProvider, error)
+
+// Registry maps a provider name (as selected via LLM_PROVIDER or
+// PipelineConfig.LLMProvider) to the Factory that builds it, so callers pick
+// a backend by name instead of switching on hardcoded constructor calls.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates an empty provider registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds (or replaces) the Factory for name.
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[name] = factory
+}
+
+// New builds the named provider with cfg.
+func (r *Registry) New(ctx context.Context, name string, cfg Config) (LLMProvider, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider %q", name)
+	}
+
+	return factory(ctx, cfg)
+}
+
+// DefaultRegistry is pre-populated with every built-in provider, keyed by
+// the same names accepted by LLM_PROVIDER and PipelineConfig.LLMProvider.
+var DefaultRegistry = NewRegistry()
+
+func init() {
+	registerRetrying("gemini", func(ctx context.Context, cfg Config) (LLMProvider, error) {
+		return NewGeminiProvider(ctx, cfg)
+	})
+	registerRetrying("groq", func(ctx context.Context, cfg Config) (LLMProvider, error) {
+		return NewGroqProvider(cfg)
+	})
+	registerRetrying("openai", func(ctx context.Context, cfg Config) (LLMProvider, error) {
+		return NewOpenAIProvider(cfg)
+	})
+	registerRetrying("anthropic", func(ctx context.Context, cfg Config) (LLMProvider, error) {
+		return NewAnthropicProvider(cfg)
+	})
+	registerRetrying("ollama", func(ctx context.Context, cfg Config) (LLMProvider, error) {
+		return NewOllamaProvider(cfg)
+	})
+	registerRetrying("llamacpp", func(ctx context.Context, cfg Config) (LLMProvider, error) {
+		return NewLlamaCppProvider(cfg)
+	})
+}
+
+// registerRetrying registers factory under name, wrapping its built provider
+// in withRetries so every DefaultRegistry provider gets the same retry/
+// backoff behavior without each factory repeating the wrap.
+func registerRetrying(name string, factory Factory) {
+	DefaultRegistry.Register(name, func(ctx context.Context, cfg Config) (LLMProvider, error) {
+		provider, err := factory(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return withRetries(provider), nil
+	})
+}
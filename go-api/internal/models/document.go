@@ -23,7 +23,9 @@ type DocumentChunk struct {
 	DocumentID uint      `json:"document_id"`
 	ChunkText  string    `json:"chunk_text"`
 	ChunkIndex int       `json:"chunk_index"`
-	CreatedAt  time.Time `json:"created_at"`
+	Metadata   string    `json:"metadata,omitempty"`              // JSON-encoded chunking provenance; see vector.Chunk.Metadata
+	DocType    string    `json:"doc_type,omitempty" gorm:"index"` // copied from the parent document for Search filtering
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
 }
 
 // CreateDocumentRequest represents the request to create a document
@@ -33,6 +35,18 @@ type CreateDocumentRequest struct {
 	SourceURL string                 `json:"source_url,omitempty" example:"https://example.com/docs"`
 	DocType   string                 `json:"doc_type,omitempty" example:"tutorial"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty" swaggertype:"object"`
+
+	// ExternalID is an optional caller-assigned identifier (e.g. a CMS page
+	// ID) that UpsertDocument matches an existing document by instead of
+	// SourceURL. Ignored by CreateDocument.
+	ExternalID string `json:"external_id,omitempty"`
+
+	// ChunkStrategy selects the chunking.Chunker used to split Content:
+	// "recursive" (default), "markdown_heading", "semantic", "token", or
+	// "late_chunking".
+	ChunkStrategy string `json:"chunk_strategy,omitempty" example:"recursive"`
+	ChunkSize     int    `json:"chunk_size,omitempty" example:"500"`
+	ChunkOverlap  int    `json:"chunk_overlap,omitempty" example:"50"`
 }
 
 // UpdateDocumentRequest represents the request to update a document
@@ -46,9 +60,12 @@ type UpdateDocumentRequest struct {
 
 // SearchRequest represents a search query
 type SearchRequest struct {
-	Query      string  `json:"query" validate:"required"`
-	SearchType string  `json:"search_type,omitempty"` // fulltext, semantic, hybrid
-	Limit      int     `json:"limit,omitempty"`
+	Query            string `json:"query" validate:"required"`
+	SearchType       string `json:"search_type,omitempty"` // fulltext, semantic, hybrid
+	Limit            int    `json:"limit,omitempty"`
+	RRFK             int    `json:"rrf_k,omitempty"`             // RRF k constant for hybrid search; defaults to 60
+	Rerank           bool   `json:"rerank,omitempty"`            // rerank hybrid results with the configured cross-encoder
+	RerankCandidates int    `json:"rerank_candidates,omitempty"` // how many fused hybrid results to rerank; defaults to 50
 }
 
 // SearchResult represents a search result with score
@@ -58,56 +75,121 @@ type SearchResult struct {
 	Snippet  string   `json:"snippet,omitempty"`
 }
 
+// ChunkHit is one matching chunk within a GroupedSearchResult's document.
+type ChunkHit struct {
+	ChunkIndex int64   `json:"chunk_index"`
+	Score      float64 `json:"score"`
+	Snippet    string  `json:"snippet,omitempty"`
+}
+
+// GroupedSearchResult is a semantic search hit grouped by document: each
+// document appears once, carrying every matching chunk instead of one
+// SearchResult per chunk (which would repeat the same document for each of
+// its matches). Chunks are sorted best score first, and the documents
+// themselves are ordered by their best-scoring chunk.
+type GroupedSearchResult struct {
+	Document Document   `json:"document"`
+	Chunks   []ChunkHit `json:"chunks"`
+}
+
 // PipelineRun represents a pipeline execution
 type PipelineRun struct {
 	ID              uint                   `json:"id"`
 	CurriculumTitle string                 `json:"curriculum_title"`
-	Status          string                 `json:"status"` // pending, processing, completed, failed
-	CurrentStage    string                 `json:"current_stage,omitempty"`  // parse, search, normalize, chunk, embed, store
-	InputData       map[string]interface{} `json:"input_data" swaggertype:"object"`
-	Config          map[string]interface{} `json:"config" swaggertype:"object"`
+	Status          string                 `json:"status"`                  // pending, processing, completed, failed
+	CurrentStage    string                 `json:"current_stage,omitempty"` // parse, search, normalize, chunk, embed, store
+	InputData       map[string]interface{} `json:"input_data" gorm:"serializer:json" swaggertype:"object"`
+	Config          map[string]interface{} `json:"config" gorm:"serializer:json" swaggertype:"object"`
 	Progress        int                    `json:"progress"` // 0-100
 	ErrorMessage    string                 `json:"error_message,omitempty"`
 	CreatedAt       time.Time              `json:"created_at"`
 	UpdatedAt       time.Time              `json:"updated_at"`
 	Topics          []CurriculumTopic      `json:"topics,omitempty"`
+
+	// Version is incremented on every write and used as the optimistic
+	// concurrency token by pipeline.PostgresStateStore.CompareAndSwapRun (the
+	// etcd backend uses etcd's own mod_revision instead, so this is unused
+	// there). Not part of the public API response.
+	Version int64 `json:"-" gorm:"default:0"`
+}
+
+// PipelineLogArtifact stores a pipeline run's flushed log lines (see
+// pipeline.LineWriter) as a JSON blob, so a run's log history survives past
+// its in-memory ring buffer and SSE subscribers being torn down. Written
+// once, when pipeline.PostgresStateStore.PutLogArtifact is called on run
+// completion.
+type PipelineLogArtifact struct {
+	PipelineRunID uint      `json:"pipeline_run_id" gorm:"primaryKey"`
+	Data          []byte    `json:"-" gorm:"type:jsonb"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // CurriculumTopic represents a topic within a curriculum
 type CurriculumTopic struct {
-	ID              uint                   `json:"id"`
-	PipelineRunID   uint                   `json:"pipeline_run_id"`
-	TopicName       string                 `json:"topic_name"`
-	OriginalContent string                 `json:"original_content,omitempty"`
-	EnrichedContent string                 `json:"enriched_content,omitempty"`
-	SearchResults   map[string]interface{} `json:"search_results,omitempty" swaggertype:"object"`
-	Status          string                 `json:"status"` // pending, searching, processing, completed, failed
-	DocumentID      *uint                  `json:"document_id,omitempty"` // Reference to created document
-	CreatedAt       time.Time              `json:"created_at"`
-	UpdatedAt       time.Time              `json:"updated_at"`
+	ID                 uint                   `json:"id"`
+	PipelineRunID      uint                   `json:"pipeline_run_id"`
+	TopicName          string                 `json:"topic_name"`
+	OriginalContent    string                 `json:"original_content,omitempty"`
+	EnrichedContent    string                 `json:"enriched_content,omitempty"`
+	SearchResults      map[string]interface{} `json:"search_results,omitempty" gorm:"serializer:json" swaggertype:"object"`
+	Status             string                 `json:"status"`                // pending, searching, processing, completed, failed, dead_letter
+	DocumentID         *uint                  `json:"document_id,omitempty"` // Reference to created document
+	LastError          string                 `json:"last_error,omitempty"`  // populated when Status is dead_letter; cleared on a successful RetryFailedTopics retry
+	Prerequisites      []string               `json:"prerequisites,omitempty" gorm:"serializer:json"`
+	LearningObjectives []string               `json:"learning_objectives,omitempty" gorm:"serializer:json"`
+	Tags               []string               `json:"tags,omitempty" gorm:"serializer:json"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
 }
 
 // PipelineConfig represents pipeline configuration
 type PipelineConfig struct {
-	WebSearchEnabled      bool   `json:"web_search_enabled"`
-	SearchResultsPerTopic int    `json:"search_results_per_topic"`
-	ChunkSize             int    `json:"chunk_size"`
-	ChunkOverlap          int    `json:"chunk_overlap"`
-	Normalize             bool   `json:"normalize"`
-	SearchEngine          string `json:"search_engine"` // duckduckgo, brave
+	WebSearchEnabled      bool    `json:"web_search_enabled"`
+	SearchResultsPerTopic int     `json:"search_results_per_topic"`
+	ChunkSize             int     `json:"chunk_size"`
+	ChunkOverlap          int     `json:"chunk_overlap"`
+	ChunkStrategy         string  `json:"chunk_strategy,omitempty"` // recursive (default), markdown_heading, semantic
+	Normalize             bool    `json:"normalize"`
+	SearchEngine          string  `json:"search_engine"`                  // duckduckgo, brave, searxng, tavily
+	LLMProvider           string  `json:"llm_provider,omitempty"`         // gemini, groq, openai, anthropic, ollama; empty uses the server default
+	LLMModel              string  `json:"llm_model,omitempty"`            // overrides the provider's default model
+	LLMTemperature        float64 `json:"llm_temperature,omitempty"`      // sampling temperature; 0 uses the provider default
+	LLMMaxTokens          int     `json:"llm_max_tokens,omitempty"`       // max output tokens; 0 uses the provider default
+	LLMSafetyThreshold    string  `json:"llm_safety_threshold,omitempty"` // provider-specific content-filter threshold name
+	TenantID              string  `json:"tenant_id,omitempty"`            // scopes the run's documents/chunks to a vector.Store partition; empty uses the default partition
+	MaxParallelTopics     int     `json:"max_parallel_topics,omitempty"`  // caps concurrent topics in the search/normalize stages; 0 uses the server default
+	MaxParallelEmbeds     int     `json:"max_parallel_embeds,omitempty"`  // caps concurrent topics in the chunk+embed stage; 0 uses the server default
+	MaxRetries            int     `json:"max_retries,omitempty"`          // retry attempts for a retryable EmbeddingClient/vector.Store failure before dead-lettering the topic; 0 uses the server default
+	InitialBackoffMs      int     `json:"initial_backoff_ms,omitempty"`   // first retry's backoff, doubled each attempt up to MaxBackoffMs; 0 uses the server default
+	MaxBackoffMs          int     `json:"max_backoff_ms,omitempty"`       // backoff ceiling; 0 uses the server default
 }
 
 // Curriculum represents a course curriculum structure
 type Curriculum struct {
-	Title   string             `json:"title" validate:"required"`
-	Modules []CurriculumModule `json:"modules" validate:"required"`
+	Title      string             `json:"title" validate:"required"`
+	Author     string             `json:"author,omitempty"`
+	Tags       []string           `json:"tags,omitempty"`
+	Difficulty string             `json:"difficulty,omitempty"`
+	Modules    []CurriculumModule `json:"modules" validate:"required"`
 }
 
 // CurriculumModule represents a module in a curriculum
 type CurriculumModule struct {
-	Name        string   `json:"name" validate:"required"`
-	Description string   `json:"description,omitempty"`
-	Topics      []string `json:"topics" validate:"required"`
+	Name        string                `json:"name" validate:"required"`
+	Description string                `json:"description,omitempty"`
+	Topics      []string              `json:"topics" validate:"required"`
+	TopicMeta   []CurriculumTopicMeta `json:"topic_meta,omitempty"`
+}
+
+// CurriculumTopicMeta carries the richer, per-topic metadata parsed from the
+// Markdown DSL (sub-topic breadcrumbs, learning objectives, inline tags)
+// that doesn't fit the flat CurriculumModule.Topics list. Entries are keyed
+// by topic name.
+type CurriculumTopicMeta struct {
+	Name               string   `json:"name"`
+	Prerequisites      []string `json:"prerequisites,omitempty"`
+	LearningObjectives []string `json:"learning_objectives,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
 }
 
 // StartPipelineRequest represents the request to start a pipeline
@@ -118,14 +200,61 @@ type StartPipelineRequest struct {
 
 // PipelineStatusResponse represents the response for pipeline status
 type PipelineStatusResponse struct {
-	ID           uint                  `json:"id"`
-	Status       string                `json:"status"`
-	CurrentStage string                `json:"current_stage"`
-	Progress     int                   `json:"progress"`
-	Stages       map[string]string     `json:"stages"`
-	ErrorMessage string                `json:"error_message,omitempty"`
-	CreatedAt    time.Time             `json:"created_at"`
-	UpdatedAt    time.Time             `json:"updated_at"`
+	ID           uint              `json:"id"`
+	Status       string            `json:"status"`
+	CurrentStage string            `json:"current_stage"`
+	Progress     int               `json:"progress"`
+	Stages       map[string]string `json:"stages"`
+	ErrorMessage string            `json:"error_message,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+
+	// Topology is the run's per-topic stage DAG (see pipeline.PipelineGraph),
+	// for a UI to render as a graph instead of a flat stage list. Only set
+	// for in-memory (non-durable) runs that are still in progress.
+	Topology *PipelineTopology `json:"topology,omitempty"`
+
+	// StageProgress gives each stage's actual unit counts (see
+	// pipeline.ProgressTracker) instead of just the coarse overall Progress
+	// percentage, keyed by stage name: search, normalize and chunk (chunk
+	// covers embed and store), so parse, embed and store have no entry. Only
+	// set for in-memory runs that are still in progress, same as Topology.
+	StageProgress map[string]StageProgress `json:"stage_progress,omitempty"`
+}
+
+// StageProgress is one stage's live unit-completion snapshot: how many of
+// its Total units (topics to search/normalize, topics to chunk+embed) have
+// completed, a rolling Throughput in units/sec over the last 30s of
+// completions, and the ETASeconds that throughput implies for the remaining
+// units. Throughput and ETASeconds are both zero/nil until enough units
+// have completed to estimate a rate.
+type StageProgress struct {
+	Completed  int      `json:"completed"`
+	Total      int      `json:"total"`
+	Throughput float64  `json:"throughput_per_sec"`
+	ETASeconds *float64 `json:"eta_seconds,omitempty"`
+}
+
+// PipelineTopologyNode is one (stage, topic) unit of work in a pipeline
+// run's DAG, e.g. search(topicA), for PipelineStatusResponse.Topology.
+type PipelineTopologyNode struct {
+	ID     string `json:"id"`
+	Stage  string `json:"stage"`
+	Topic  string `json:"topic"`
+	Status string `json:"status"`
+}
+
+// PipelineTopologyEdge is a "from must complete before to can start"
+// dependency edge between two PipelineTopologyNodes.
+type PipelineTopologyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PipelineTopology is the DAG view of a pipeline run's per-topic stage work.
+type PipelineTopology struct {
+	Nodes []PipelineTopologyNode `json:"nodes"`
+	Edges []PipelineTopologyEdge `json:"edges"`
 }
 
 // PipelineResultsResponse represents the response for pipeline results
@@ -134,3 +263,37 @@ type PipelineResultsResponse struct {
 	Documents   []Document  `json:"documents"`
 	TotalChunks int         `json:"total_chunks"`
 }
+
+// PipelineJob represents a single unit of pipeline work (one stage for one
+// topic, or one run-level stage such as parse) persisted so the pipeline
+// survives API process restarts. Workers claim rows with
+// `SELECT ... FOR UPDATE SKIP LOCKED` keyed by (pipeline_run_id, topic_id,
+// stage).
+type PipelineJob struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	PipelineRunID uint      `json:"pipeline_run_id" gorm:"index"`
+	TopicID       *uint     `json:"topic_id,omitempty"`
+	Stage         string    `json:"stage"`               // parse, search, normalize, chunk (chunk covers chunk+embed+store)
+	Status        string    `json:"status" gorm:"index"` // queued, processing, completed, failed, cancelled, dead_letter
+	Attempts      int       `json:"attempts"`
+	MaxAttempts   int       `json:"max_attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// UploadJobStatus reports a single document upload's async processing
+// state, for GET /documents/jobs/:id. Unlike PipelineJob, this isn't
+// Postgres-backed - see handlers.uploadJobManager - so GET
+// /documents/jobs/:id returns a 404 not_found error after an API restart for
+// a job that was still in flight.
+type UploadJobStatus struct {
+	ID         string         `json:"id"`
+	Status     string         `json:"status"` // queued, processing, completed, failed
+	Stage      string         `json:"stage"`  // converting, chunking, embedding, indexing
+	DocumentID uint           `json:"document_id,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,68 @@
+// Package objectstore persists raw uploaded files: the async upload worker
+// (see handlers.uploadJobManager) stores each upload's bytes here before
+// converting them.
+package objectstore
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Store persists raw file bytes under key and returns a URI identifying
+// where they landed (s3://bucket/key or file://path).
+type Store interface {
+	Put(key string, r io.Reader) (uri string, err error)
+}
+
+// NewStoreFromEnv returns an S3Store when S3_BUCKET is set, otherwise a
+// LocalDiskStore rooted at UPLOAD_DIR (default "./uploads"), so upload
+// works out of the box without any object storage configured and upgrades
+// to S3/MinIO by setting a handful of env vars.
+func NewStoreFromEnv() (Store, error) {
+	if bucket := os.Getenv("S3_BUCKET"); bucket != "" {
+		return NewS3Store(bucket)
+	}
+
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = "./uploads"
+	}
+	return NewLocalDiskStore(dir)
+}
+
+// LocalDiskStore writes uploads under a root directory on the local
+// filesystem, for deployments without S3/MinIO configured.
+type LocalDiskStore struct {
+	root string
+}
+
+// NewLocalDiskStore creates a LocalDiskStore rooted at root, creating it if
+// it doesn't already exist.
+func NewLocalDiskStore(root string) (*LocalDiskStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory %q: %w", root, err)
+	}
+	return &LocalDiskStore{root: root}, nil
+}
+
+// Put writes r to root/key, returning a file:// URI.
+func (s *LocalDiskStore) Put(key string, r io.Reader) (string, error) {
+	path := filepath.Join(s.root, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload subdirectory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return "", fmt.Errorf("failed to write upload file: %w", err)
+	}
+
+	return "file://" + path, nil
+}
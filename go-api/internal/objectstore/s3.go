@@ -0,0 +1,59 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Store persists uploads to an S3-compatible bucket. S3_ENDPOINT lets it
+// target MinIO or another S3-compatible host instead of AWS; AWS_REGION
+// defaults to "us-east-1" when unset, which MinIO ignores but the SDK
+// requires regardless.
+type S3Store struct {
+	client *s3.Client
+	bucket string
+}
+
+// NewS3Store creates an S3Store for bucket, loading credentials the usual
+// AWS SDK way (env vars, shared config/credentials files, or an instance
+// role) rather than requiring them to be passed in explicitly.
+func NewS3Store(bucket string) (*S3Store, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := os.Getenv("S3_ENDPOINT"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true // required by MinIO and most non-AWS S3-compatible hosts
+		}
+	})
+
+	return &S3Store{client: client, bucket: bucket}, nil
+}
+
+// Put uploads r to bucket/key, returning an s3:// URI.
+func (s *S3Store) Put(key string, r io.Reader) (string, error) {
+	_, err := s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   r,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %q to S3: %w", key, err)
+	}
+
+	return fmt.Sprintf("s3://%s/%s", s.bucket, key), nil
+}
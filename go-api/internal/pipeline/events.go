@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+)
+
+// EventType identifies the kind of progress event a pipeline run emits over
+// its SSE stream.
+type EventType string
+
+const (
+	EventStageStarted      EventType = "stage_started"
+	EventStageProgress     EventType = "stage_progress"
+	EventChunkEmbedded     EventType = "chunk_embedded"
+	EventDocumentPersisted EventType = "document_persisted"
+	EventStageCompleted    EventType = "stage_completed"
+	EventPipelineFailed    EventType = "pipeline_failed"
+	EventPipelineCompleted EventType = "pipeline_completed"
+
+	// EventStageProgressDetail carries a ProgressTracker unit-completion
+	// snapshot (see Event.StageProgress), distinct from EventStageProgress'
+	// coarse 0-100 overall percentage, so a UI can render a live progress
+	// bar per stage instead of one aggregate number.
+	EventStageProgressDetail EventType = "stage_progress_detail"
+)
+
+// Event is one entry in a pipeline run's SSE stream. ID is assigned by the
+// EventBroker and is what clients echo back via Last-Event-ID to resume.
+type Event struct {
+	ID        uint64    `json:"id"`
+	Type      EventType `json:"type"`
+	Stage     string    `json:"stage,omitempty"`
+	Progress  int       `json:"progress,omitempty"`
+	ItemIndex int       `json:"item_index,omitempty"`
+	ItemTotal int       `json:"item_total,omitempty"`
+	Topic     string    `json:"topic,omitempty"`
+	Code      errs.Code `json:"code,omitempty"`
+	Message   string    `json:"message,omitempty"`
+
+	// StageProgress carries a ProgressTracker snapshot on an
+	// EventStageProgressDetail event; unset on every other EventType.
+	StageProgress *models.StageProgress `json:"stage_progress,omitempty"`
+}
+
+// eventBufferSize bounds how far behind a slow SSE subscriber can fall
+// before Publish starts dropping the oldest unread event for it, so one
+// stalled client can't block or unbound the publisher.
+const eventBufferSize = 64
+
+// eventBacklogSize is how many recent events per run are retained for
+// Last-Event-ID resume after a client reconnects.
+const eventBacklogSize = 200
+
+// hubEvictionDelay is how long a run's hub (and its backlog) is kept after a
+// terminal event, so a client that's mid-reconnect can still resume before
+// it's reclaimed. Without this, a long-lived server would retain one hub per
+// pipeline run it had ever processed.
+const hubEvictionDelay = 5 * time.Minute
+
+// runHub fans out one pipeline run's events to its current SSE subscribers
+// and keeps a bounded backlog for resume.
+type runHub struct {
+	mu      sync.Mutex
+	nextID  uint64
+	backlog []Event
+	subs    map[chan Event]struct{}
+}
+
+// EventBroker publishes pipeline progress events and fans them out to SSE
+// subscribers, keyed by pipeline run ID so a slow subscriber on one run
+// can't back up events for another. It's purely in-memory: events aren't
+// persisted past process restart.
+type EventBroker struct {
+	mu   sync.Mutex
+	hubs map[uint]*runHub
+}
+
+// NewEventBroker creates an empty EventBroker.
+func NewEventBroker() *EventBroker {
+	return &EventBroker{hubs: make(map[uint]*runHub)}
+}
+
+func (b *EventBroker) hubFor(runID uint) *runHub {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h, ok := b.hubs[runID]
+	if !ok {
+		h = &runHub{subs: make(map[chan Event]struct{})}
+		b.hubs[runID] = h
+	}
+	return h
+}
+
+// Publish assigns ev the run's next event ID, appends it to the resume
+// backlog, and delivers it to every current subscriber. A subscriber whose
+// channel is full has its oldest buffered event dropped to make room, rather
+// than blocking the publisher on a slow consumer.
+func (b *EventBroker) Publish(runID uint, ev Event) {
+	h := b.hubFor(runID)
+
+	h.mu.Lock()
+	h.nextID++
+	ev.ID = h.nextID
+	h.backlog = append(h.backlog, ev)
+	if len(h.backlog) > eventBacklogSize {
+		h.backlog = h.backlog[len(h.backlog)-eventBacklogSize:]
+	}
+	subs := make([]chan Event, 0, len(h.subs))
+	for ch := range h.subs {
+		subs = append(subs, ch)
+	}
+	h.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- ev:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+
+	if ev.Type == EventPipelineCompleted || ev.Type == EventPipelineFailed {
+		time.AfterFunc(hubEvictionDelay, func() {
+			b.mu.Lock()
+			delete(b.hubs, runID)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Subscribe registers a new SSE subscriber for runID. It returns the live
+// event channel, any backlogged events with ID greater than lastEventID (for
+// Last-Event-ID resume), and an unsubscribe func the caller must run when
+// the stream ends.
+func (b *EventBroker) Subscribe(runID uint, lastEventID uint64) (<-chan Event, []Event, func()) {
+	h := b.hubFor(runID)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []Event
+	for _, ev := range h.backlog {
+		if ev.ID > lastEventID {
+			backlog = append(backlog, ev)
+		}
+	}
+
+	ch := make(chan Event, eventBufferSize)
+	h.subs[ch] = struct{}{}
+
+	return ch, backlog, func() {
+		h.mu.Lock()
+		delete(h.subs, ch)
+		h.mu.Unlock()
+	}
+}
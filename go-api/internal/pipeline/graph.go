@@ -0,0 +1,163 @@
+package pipeline
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+)
+
+// pipelineNode is one (stage, topic) unit of work in a PipelineGraph, e.g.
+// search(topicA) or normalize(topicA).
+type pipelineNode struct {
+	id        string
+	stage     string
+	topic     string
+	dependsOn string // the node this one waits on, within the same topic; "" for a topic's first stage
+	status    string
+}
+
+// nodeID is the key a topic's node for stage is stored under.
+func nodeID(stage, topic string) string {
+	return fmt.Sprintf("%s(%s)", stage, topic)
+}
+
+// PipelineGraph is the DAG of per-topic stage work for one pipeline run,
+// modeled after Argo-style pipelines: every topic runs through stages in the
+// same order (e.g. search -> normalize -> chunk), but topics don't depend on
+// each other, so runGraphStage can fan them out concurrently within a stage
+// instead of the original sequential per-topic loops. It does not model
+// cross-stage pipelining (topic B starting search while topic A is already
+// normalizing) since the stages here are called one at a time from
+// processPipeline; see StartPipeline/NewPipelineGraph.
+type PipelineGraph struct {
+	mu    sync.Mutex
+	nodes map[string]*pipelineNode
+	order []string // insertion order, for a stable Snapshot
+}
+
+// NewPipelineGraph builds the graph for topics: for each topic, one node per
+// stage in stages, chained in order so stage i depends on stage i-1 for that
+// same topic.
+func NewPipelineGraph(topics []string, stages []string) *PipelineGraph {
+	g := &PipelineGraph{nodes: make(map[string]*pipelineNode, len(topics)*len(stages))}
+
+	for _, topic := range topics {
+		var prev string
+		for _, stage := range stages {
+			id := nodeID(stage, topic)
+			g.nodes[id] = &pipelineNode{id: id, stage: stage, topic: topic, dependsOn: prev, status: StatusPending}
+			g.order = append(g.order, id)
+			prev = id
+		}
+	}
+
+	return g
+}
+
+// ReadyForStage returns the topics whose node for stage is pending and whose
+// dependency (the same topic's previous stage, if any) has completed.
+func (g *PipelineGraph) ReadyForStage(stage string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var topics []string
+	for _, id := range g.order {
+		node := g.nodes[id]
+		if node.stage != stage || node.status != StatusPending {
+			continue
+		}
+		if node.dependsOn == "" || g.nodes[node.dependsOn].status == StatusCompleted {
+			topics = append(topics, node.topic)
+		}
+	}
+	return topics
+}
+
+// SetStatus updates the status of topic's node for stage.
+func (g *PipelineGraph) SetStatus(stage, topic, status string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if node, ok := g.nodes[nodeID(stage, topic)]; ok {
+		node.status = status
+	}
+}
+
+// CompleteStage marks every node for stage as completed without running it,
+// for a stage the run config skips entirely (e.g. WebSearchEnabled=false),
+// so the next stage's dependencies are still satisfied.
+func (g *PipelineGraph) CompleteStage(stage string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, id := range g.order {
+		if node := g.nodes[id]; node.stage == stage {
+			node.status = StatusCompleted
+		}
+	}
+}
+
+// Snapshot renders the graph's current node statuses and within-topic
+// dependency edges, for PipelineStatusResponse.Topology.
+func (g *PipelineGraph) Snapshot() models.PipelineTopology {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	topology := models.PipelineTopology{
+		Nodes: make([]models.PipelineTopologyNode, 0, len(g.order)),
+	}
+	for _, id := range g.order {
+		node := g.nodes[id]
+		topology.Nodes = append(topology.Nodes, models.PipelineTopologyNode{
+			ID: node.id, Stage: node.stage, Topic: node.topic, Status: node.status,
+		})
+		if node.dependsOn != "" {
+			topology.Edges = append(topology.Edges, models.PipelineTopologyEdge{From: node.dependsOn, To: node.id})
+		}
+	}
+	return topology
+}
+
+// runGraphStage drains every currently-ready topic for stage from graph and
+// runs work on each with up to concurrency goroutines in flight at once,
+// marking each topic's node completed or failed as it finishes. The
+// concurrency cap is also this stage's rate limit: it replaces the fixed
+// time.Sleep(1*time.Second) the sequential implementation used to avoid
+// hammering search/embedding upstreams, since no more than concurrency
+// requests for this stage are ever in flight together. Returns one error per
+// failed topic, wrapped with its node ID.
+func runGraphStage(graph *PipelineGraph, stage string, concurrency int, work func(topic string) error) []error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	topics := graph.ReadyForStage(stage)
+	if len(topics) == 0 {
+		return nil
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+
+	for _, topic := range topics {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(topic string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := work(topic); err != nil {
+				graph.SetStatus(stage, topic, StatusFailed)
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", nodeID(stage, topic), err))
+				mu.Unlock()
+				return
+			}
+			graph.SetStatus(stage, topic, StatusCompleted)
+		}(topic)
+	}
+
+	wg.Wait()
+	return errs
+}
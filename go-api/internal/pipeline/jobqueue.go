@@ -0,0 +1,176 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// Job statuses
+const (
+	JobStatusQueued     = "queued"
+	JobStatusProcessing = "processing"
+	JobStatusCompleted  = "completed"
+	JobStatusFailed     = "failed"
+	JobStatusCancelled  = "cancelled"
+	JobStatusDeadLetter = "dead_letter"
+)
+
+const defaultMaxAttempts = 5
+
+// JobQueue is a Postgres-backed queue of pipeline_jobs rows. Workers claim
+// rows with `SELECT ... FOR UPDATE SKIP LOCKED` so multiple worker processes
+// can pull from the same table without double-processing a job.
+type JobQueue struct {
+	db *gorm.DB
+}
+
+// NewJobQueue wraps an already-initialized *gorm.DB (see database.Initialize).
+func NewJobQueue(db *gorm.DB) *JobQueue {
+	return &JobQueue{db: db}
+}
+
+// Enqueue inserts a new queued job for a pipeline run stage. topicID is nil
+// for run-level stages (currently just "parse").
+func (q *JobQueue) Enqueue(pipelineRunID uint, topicID *uint, stage string) (*models.PipelineJob, error) {
+	job := &models.PipelineJob{
+		PipelineRunID: pipelineRunID,
+		TopicID:       topicID,
+		Stage:         stage,
+		Status:        JobStatusQueued,
+		MaxAttempts:   defaultMaxAttempts,
+		NextAttemptAt: time.Now(),
+	}
+
+	if err := q.db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ClaimNext atomically claims the oldest ready job, marking it processing,
+// or returns (nil, nil) if there's nothing to do.
+func (q *JobQueue) ClaimNext() (*models.PipelineJob, error) {
+	var job models.PipelineJob
+
+	err := q.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(`
+			SELECT * FROM pipeline_jobs
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		`, JobStatusQueued, time.Now()).Scan(&job)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			job = models.PipelineJob{}
+			return nil
+		}
+
+		return tx.Model(&models.PipelineJob{}).
+			Where("id = ?", job.ID).
+			Updates(map[string]interface{}{
+				"status":     JobStatusProcessing,
+				"updated_at": time.Now(),
+			}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	if job.ID == 0 {
+		return nil, nil
+	}
+
+	return &job, nil
+}
+
+// Complete marks a job as completed.
+func (q *JobQueue) Complete(jobID uint) error {
+	return q.db.Model(&models.PipelineJob{}).
+		Where("id = ?", jobID).
+		Updates(map[string]interface{}{"status": JobStatusCompleted, "updated_at": time.Now()}).Error
+}
+
+// Fail records a job failure. If the job has exhausted max_attempts, or
+// forceDeadLetter is set because the error isn't one a retry could fix, it's
+// moved to the dead_letter state; otherwise it's requeued with an
+// exponential backoff + jitter delay. attempts is incremented by exactly one
+// regardless, so the persisted count always reflects how many times the job
+// actually ran.
+func (q *JobQueue) Fail(job *models.PipelineJob, jobErr error, forceDeadLetter bool) error {
+	attempts := job.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": jobErr.Error(),
+		"updated_at": time.Now(),
+	}
+
+	if forceDeadLetter || attempts >= job.MaxAttempts {
+		updates["status"] = JobStatusDeadLetter
+	} else {
+		updates["status"] = JobStatusQueued
+		updates["next_attempt_at"] = time.Now().Add(backoffDelay(attempts))
+	}
+
+	return q.db.Model(&models.PipelineJob{}).Where("id = ?", job.ID).Updates(updates).Error
+}
+
+// CancelRun marks every queued/processing job for a run as cancelled so
+// workers stop picking up new work for it.
+func (q *JobQueue) CancelRun(pipelineRunID uint) error {
+	return q.db.Model(&models.PipelineJob{}).
+		Where("pipeline_run_id = ? AND status IN ?", pipelineRunID, []string{JobStatusQueued, JobStatusProcessing}).
+		Updates(map[string]interface{}{"status": JobStatusCancelled, "updated_at": time.Now()}).Error
+}
+
+// RecoverStuckJobs resets jobs left in "processing" by a crashed worker back
+// to "queued" so they get picked up again. Call on API boot.
+func (q *JobQueue) RecoverStuckJobs() (int64, error) {
+	result := q.db.Model(&models.PipelineJob{}).
+		Where("status = ?", JobStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":          JobStatusQueued,
+			"next_attempt_at": time.Now(),
+			"updated_at":      time.Now(),
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+// RecoverExpiredLeases is RecoverStuckJobs' periodic counterpart: unlike
+// RecoverStuckJobs, which is only safe at boot (nothing else should be
+// mid-flight yet), this only reclaims jobs whose updated_at - the worker's
+// lease, set when ClaimNext claims the row - is older than ttl. The lease
+// is not renewed while the job runs, so a job still being processed after
+// ttl has passed is requeued as well. Call on a ticker to detect a worker that
+// crashed or got killed mid-job without a full process restart.
+func (q *JobQueue) RecoverExpiredLeases(ttl time.Duration) (int64, error) {
+	result := q.db.Model(&models.PipelineJob{}).
+		Where("status = ? AND updated_at < ?", JobStatusProcessing, time.Now().Add(-ttl)).
+		Updates(map[string]interface{}{
+			"status":          JobStatusQueued,
+			"next_attempt_at": time.Now(),
+			"updated_at":      time.Now(),
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+// backoffDelay returns an exponential backoff with jitter for the given
+// attempt number (1-indexed), capped at one minute.
+func backoffDelay(attempt int) time.Duration {
+	base := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+	base += time.Duration(rand.Int63n(int64(base)/5 + 1))
+	if base > time.Minute {
+		base = time.Minute
+	}
+	return base
+}
@@ -0,0 +1,166 @@
+package pipeline
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel identifies the severity of a pipeline log line.
+type LogLevel string
+
+const (
+	LogInfo  LogLevel = "info"
+	LogWarn  LogLevel = "warn"
+	LogError LogLevel = "error"
+)
+
+// LogLine is one structured entry in a pipeline run's log stream.
+type LogLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Stage     string    `json:"stage,omitempty"`
+	Topic     string    `json:"topic,omitempty"`
+	Level     LogLevel  `json:"level"`
+	Message   string    `json:"message"`
+}
+
+// logRingBufferSize bounds how many lines a run's LineWriter retains for a
+// client that starts streaming mid-run, and for the logs.json artifact
+// flushed on completion; older lines are dropped once it fills.
+const logRingBufferSize = 2000
+
+// stdoutLog is where LineWriter echoes every line; the standard logger
+// writes to stderr.
+var stdoutLog = log.New(os.Stdout, "", log.LstdFlags)
+
+// LineWriter is a per-run log sink modeled on Woodpecker's rpc.NewLineWriter:
+// every line is written to stdout (so it still shows up in the process's own
+// logs), appended to a bounded in-memory ring buffer, and fanned out to any
+// attached SSE subscribers - all after scanning the line for a configured
+// list of secrets and replacing each occurrence with "***", so a topic name
+// or search result that happens to echo back an API key never reaches a
+// client or the ring buffer that gets flushed as an artifact.
+type LineWriter struct {
+	pipelineRunID uint
+	secrets       []string
+
+	mu    sync.Mutex
+	lines []LogLine
+	subs  map[chan LogLine]struct{}
+}
+
+// newLineWriter creates a LineWriter for pipelineRunID. secrets is the list
+// of literal values to redact from every line before it's written or
+// published; empty entries are ignored.
+func newLineWriter(pipelineRunID uint, secrets []string) *LineWriter {
+	filtered := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		if s != "" {
+			filtered = append(filtered, s)
+		}
+	}
+	return &LineWriter{
+		pipelineRunID: pipelineRunID,
+		secrets:       filtered,
+		subs:          make(map[chan LogLine]struct{}),
+	}
+}
+
+// redact replaces every configured secret found in line with "***".
+func (w *LineWriter) redact(line string) string {
+	for _, secret := range w.secrets {
+		line = strings.ReplaceAll(line, secret, "***")
+	}
+	return line
+}
+
+// Printf formats a log line, redacts it, and emits it to stdout, the ring
+// buffer, and any live subscribers. stage/topic may be empty for run-level
+// lines that aren't scoped to a single topic.
+func (w *LineWriter) Printf(stage, topic string, level LogLevel, format string, args ...interface{}) {
+	message := w.redact(fmt.Sprintf(format, args...))
+
+	entry := LogLine{
+		Timestamp: time.Now(),
+		Stage:     stage,
+		Topic:     topic,
+		Level:     level,
+		Message:   message,
+	}
+
+	stdoutLog.Printf("[pipeline %d] %s", w.pipelineRunID, message)
+
+	w.mu.Lock()
+	w.lines = append(w.lines, entry)
+	if len(w.lines) > logRingBufferSize {
+		w.lines = w.lines[len(w.lines)-logRingBufferSize:]
+	}
+	subs := make([]chan LogLine, 0, len(w.subs))
+	for ch := range w.subs {
+		subs = append(subs, ch)
+	}
+	w.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- entry:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- entry:
+			default:
+			}
+		}
+	}
+}
+
+// Subscribe registers a new log subscriber, returning its channel, the
+// backlog collected so far (for a client that starts streaming mid-run),
+// and an unsubscribe func the caller must run when the stream ends.
+func (w *LineWriter) Subscribe() (<-chan LogLine, []LogLine, func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	backlog := make([]LogLine, len(w.lines))
+	copy(backlog, w.lines)
+
+	ch := make(chan LogLine, eventBufferSize)
+	w.subs[ch] = struct{}{}
+
+	return ch, backlog, func() {
+		w.mu.Lock()
+		delete(w.subs, ch)
+		w.mu.Unlock()
+	}
+}
+
+// Lines returns a snapshot of every line currently retained, for flushing to
+// a logs.json artifact on run completion.
+func (w *LineWriter) Lines() []LogLine {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	out := make([]LogLine, len(w.lines))
+	copy(out, w.lines)
+	return out
+}
+
+// defaultSecretsFromEnv collects the credential values the pipeline's
+// dependencies actually read from the environment (search provider API
+// keys, the Milvus token) so a LineWriter can scrub them out of log lines.
+// None of these live on PipelineConfig itself - it only carries provider
+// and search-engine *names*, never the credentials behind them - so this
+// reads the same env vars those dependencies do instead.
+func defaultSecretsFromEnv() []string {
+	return []string{
+		os.Getenv("MILVUS_TOKEN"),
+		os.Getenv("BRAVE_API_KEY"),
+		os.Getenv("TAVILY_API_KEY"),
+	}
+}
@@ -1,15 +1,22 @@
 package pipeline
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/chunking"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/embedding_client"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/llm"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
 	"github.com/kavishankarks/itp-rag-processor/go-api/internal/vector"
+	"gorm.io/gorm"
 )
 
 // Pipeline stages
@@ -28,14 +35,60 @@ const (
 	StatusProcessing = "processing"
 	StatusCompleted  = "completed"
 	StatusFailed     = "failed"
+
+	// StatusDeadLetter marks a topic (never a whole run) that exhausted
+	// withRetry's attempts in chunkAndEmbedTopics. The topic's LastError
+	// records the final cause; RetryFailedTopics re-runs just the
+	// dead-lettered topics without rerunning the whole pipeline.
+	StatusDeadLetter = "dead_letter"
 )
 
+// leaseRenewInterval is how often a running in-memory pipeline goroutine
+// refreshes its lease (see runHandle). The janitor started by
+// StartLeaseJanitor is given its own TTL, which should be a healthy multiple
+// of this interval so a couple of missed renewals (GC pause, slow stage)
+// don't trip it.
+const leaseRenewInterval = 10 * time.Second
+
+// runHandle tracks the context.CancelFunc and lease for one in-progress
+// in-memory pipeline run, so CancelPipeline can abort its goroutine (and any
+// in-flight EmbeddingClient call) instead of just flipping its status while
+// the goroutine keeps running, and so a janitor can detect one that stopped
+// renewing its lease (e.g. panicked or deadlocked) without a process
+// restart. It has no durable counterpart: an in-memory run's state doesn't
+// survive a restart either way, unlike the job-queue path (see
+// RecoverStuckJobs/RecoverIncompleteRuns).
+type runHandle struct {
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	lease time.Time
+}
+
+func (h *runHandle) renew() {
+	h.mu.Lock()
+	h.lease = time.Now()
+	h.mu.Unlock()
+}
+
+func (h *runHandle) expired(ttl time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return time.Since(h.lease) > ttl
+}
+
 // Orchestrator manages the RAG pipeline execution
 type Orchestrator struct {
 	embeddingClient *embedding_client.EmbeddingClient
-	milvusClient    *vector.MilvusClient
+	store           vector.Store
 	parser          *CurriculumParser
 
+	// searchProvider performs the enrichment stage's web search directly in
+	// Go when configured (see NewSearchProviderFromEnv). When nil, the
+	// enrichment stage falls back to embeddingClient.EnrichTopic, which
+	// delegates web search to the embedding microservice.
+	searchProvider SearchProvider
+
 	// In-memory state storage
 	runsMu sync.RWMutex
 	runs   map[uint]*models.PipelineRun
@@ -43,22 +96,244 @@ type Orchestrator struct {
 	topicsMu sync.RWMutex
 	topics   map[uint][]*models.CurriculumTopic
 
+	// graphs holds each in-progress in-memory run's PipelineGraph, so
+	// GetPipelineStatus can expose a topology view while the run is active.
+	// Entries are removed once the run reaches a terminal status.
+	graphsMu sync.RWMutex
+	graphs   map[uint]*PipelineGraph
+
+	// active holds the runHandle (cancel func + lease) for every in-progress
+	// in-memory run, so CancelPipeline and the lease janitor can reach a
+	// running goroutine. Entries are removed once the run reaches a terminal
+	// status, same lifecycle as graphs.
+	activeMu sync.Mutex
+	active   map[uint]*runHandle
+
 	// ID counters
 	nextRunID   uint
 	nextTopicID uint
+
+	// Optional durable execution. When set (via SetJobQueue), pipeline runs
+	// are persisted to Postgres and driven stage-by-stage by a WorkerPool
+	// instead of the in-memory goroutine, so runs survive process restarts.
+	db       *gorm.DB
+	jobQueue *JobQueue
+
+	// stateStore, when set (via SetStateStore), backs updatePipelineStatus's
+	// run writes with a CompareAndSwapRun instead of a plain mutex-guarded
+	// map mutation, and backs ListPipelines's read. It's independent of
+	// jobQueue: a deployment can run the durable job-queue execution path
+	// without a StateStore (falling back to o.db directly, as before), or
+	// layer a StateStore on top of either execution path to share run state
+	// across API replicas. Per-topic state for in-memory runs (o.topics)
+	// isn't migrated onto StateStore yet - only run-level status/progress
+	// writes are, since those are what concurrent workers race on.
+	stateStore StateStore
+
+	// logs holds the per-run LineWriter for every in-progress in-memory run
+	// (see logWriterFor); entries are removed once flushLogs persists the
+	// run's final lines as its logs.json artifact. logArtifacts is the
+	// fallback artifact store used when no StateStore is configured, mirror
+	// of logArtifactsMu-guarded map used elsewhere for in-memory-only state.
+	logsMu         sync.Mutex
+	logs           map[uint]*LineWriter
+	logArtifactsMu sync.Mutex
+	logArtifacts   map[uint][]byte
+
+	// progress holds the per-run ProgressTracker for every in-progress
+	// in-memory run, so GetPipelineStatus can expose fine-grained per-stage
+	// completed/total/throughput/ETA instead of just the coarse overall
+	// Progress percentage. Entries are removed once the run reaches a
+	// terminal status, same lifecycle as graphs.
+	progressMu sync.RWMutex
+	progress   map[uint]*ProgressTracker
+
+	// events fans out per-run progress events to SSE subscribers (see
+	// Events and events.go). It's independent of the in-memory/durable
+	// execution split above: both paths publish to it.
+	events *EventBroker
 }
 
-// NewOrchestrator creates a new pipeline orchestrator
-func NewOrchestrator(embeddingClient *embedding_client.EmbeddingClient, milvusClient *vector.MilvusClient) *Orchestrator {
+// NewOrchestrator creates a new pipeline orchestrator. searchProvider may be
+// nil, in which case the enrichment stage uses embeddingClient.EnrichTopic
+// as before; see NewSearchProviderFromEnv.
+func NewOrchestrator(embeddingClient *embedding_client.EmbeddingClient, store vector.Store, searchProvider SearchProvider) *Orchestrator {
 	return &Orchestrator{
 		embeddingClient: embeddingClient,
-		milvusClient:    milvusClient,
+		store:           store,
 		parser:          NewCurriculumParser(),
+		searchProvider:  searchProvider,
 		runs:            make(map[uint]*models.PipelineRun),
 		topics:          make(map[uint][]*models.CurriculumTopic),
+		graphs:          make(map[uint]*PipelineGraph),
+		active:          make(map[uint]*runHandle),
+		logs:            make(map[uint]*LineWriter),
+		logArtifacts:    make(map[uint][]byte),
+		progress:        make(map[uint]*ProgressTracker),
 		nextRunID:       1,
 		nextTopicID:     1,
+		events:          NewEventBroker(),
+	}
+}
+
+// Events subscribes to pipelineRunID's progress events, for the SSE handler.
+// lastEventID replays any backlogged events after it (Last-Event-ID resume);
+// pass 0 for a fresh stream. The caller must invoke the returned unsubscribe
+// func when the stream ends.
+func (o *Orchestrator) Events(pipelineRunID uint, lastEventID uint64) (<-chan Event, []Event, func()) {
+	return o.events.Subscribe(pipelineRunID, lastEventID)
+}
+
+func (o *Orchestrator) publishEvent(pipelineRunID uint, ev Event) {
+	o.events.Publish(pipelineRunID, ev)
+}
+
+// SetJobQueue wires a durable, Postgres-backed job queue into the
+// orchestrator. Once configured, StartPipeline persists runs and topics to
+// the database and enqueues a job for the first stage instead of spawning a
+// goroutine; a WorkerPool claims and executes jobs stage-by-stage. Without a
+// job queue the orchestrator keeps the original in-memory behavior, so
+// Milvus-only deployments without Postgres are unaffected.
+func (o *Orchestrator) SetJobQueue(jq *JobQueue, db *gorm.DB) {
+	o.jobQueue = jq
+	o.db = db
+}
+
+// SetStateStore wires an optional StateStore into the orchestrator. Once
+// configured, updatePipelineStatus uses it for CAS-guarded run writes and
+// ListPipelines uses it to list runs, instead of the in-memory map or (on
+// the durable job-queue path) direct gorm access to o.db. See
+// PostgresStateStore and EtcdStateStore.
+func (o *Orchestrator) SetStateStore(ss StateStore) {
+	o.stateStore = ss
+}
+
+// logWriterFor returns pipelineRunID's LineWriter, creating one on first
+// use so processPipeline and the stage functions it calls - which only ever
+// have the run's ID in hand, not a writer passed down through every layer -
+// can share the same instance.
+func (o *Orchestrator) logWriterFor(pipelineRunID uint) *LineWriter {
+	o.logsMu.Lock()
+	defer o.logsMu.Unlock()
+
+	w, ok := o.logs[pipelineRunID]
+	if !ok {
+		w = newLineWriter(pipelineRunID, defaultSecretsFromEnv())
+		o.logs[pipelineRunID] = w
+	}
+	return w
+}
+
+// flushLogs persists pipelineRunID's accumulated log lines as its logs.json
+// artifact (state store if configured, otherwise an in-memory fallback) and
+// drops the live LineWriter, so PipelineLogs keeps working after the run
+// exits o.active. GetPipelineResults does not include these log lines.
+func (o *Orchestrator) flushLogs(pipelineRunID uint) {
+	o.logsMu.Lock()
+	w, ok := o.logs[pipelineRunID]
+	delete(o.logs, pipelineRunID)
+	o.logsMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	data, err := json.Marshal(w.Lines())
+	if err != nil {
+		log.Printf("Pipeline %d: failed to marshal log artifact: %v", pipelineRunID, err)
+		return
+	}
+
+	if o.stateStore != nil {
+		if err := o.stateStore.PutLogArtifact(context.Background(), pipelineRunID, data); err != nil {
+			log.Printf("Pipeline %d: failed to persist log artifact: %v", pipelineRunID, err)
+		}
+		return
+	}
+
+	o.logArtifactsMu.Lock()
+	o.logArtifacts[pipelineRunID] = data
+	o.logArtifactsMu.Unlock()
+}
+
+// SubscribeLogs subscribes to pipelineRunID's live log stream for as long as
+// the run is active, returning the backlog collected so far plus a channel
+// of new lines and an unsubscribe func. If the run has already finished (no
+// live LineWriter), the returned channel is nil and callers should fall
+// back to PipelineLogs for the flushed history instead.
+func (o *Orchestrator) SubscribeLogs(pipelineRunID uint) (<-chan LogLine, []LogLine, func()) {
+	o.logsMu.Lock()
+	w, ok := o.logs[pipelineRunID]
+	o.logsMu.Unlock()
+
+	if !ok {
+		return nil, nil, func() {}
+	}
+
+	return w.Subscribe()
+}
+
+// PipelineLogs returns pipelineRunID's log lines: its live LineWriter's
+// lines if the run is still in progress, otherwise whatever was flushed to
+// its logs.json artifact on completion. Returns a nil slice (no error) if
+// neither exists, e.g. the run predates this feature.
+func (o *Orchestrator) PipelineLogs(pipelineRunID uint) ([]LogLine, error) {
+	o.logsMu.Lock()
+	w, ok := o.logs[pipelineRunID]
+	o.logsMu.Unlock()
+	if ok {
+		return w.Lines(), nil
+	}
+
+	var data []byte
+	if o.stateStore != nil {
+		artifact, err := o.stateStore.GetLogArtifact(context.Background(), pipelineRunID)
+		if err != nil {
+			return nil, err
+		}
+		data = artifact
+	} else {
+		o.logArtifactsMu.Lock()
+		data = o.logArtifacts[pipelineRunID]
+		o.logArtifactsMu.Unlock()
+	}
+
+	if data == nil {
+		return nil, nil
 	}
+
+	var lines []LogLine
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return nil, fmt.Errorf("failed to decode log artifact for pipeline run %d: %w", pipelineRunID, err)
+	}
+	return lines, nil
+}
+
+// progressTrackerFor returns pipelineRunID's ProgressTracker, creating one on
+// first use (same lazy-creation pattern as logWriterFor).
+func (o *Orchestrator) progressTrackerFor(pipelineRunID uint) *ProgressTracker {
+	o.progressMu.Lock()
+	defer o.progressMu.Unlock()
+
+	t, ok := o.progress[pipelineRunID]
+	if !ok {
+		t = NewProgressTracker()
+		o.progress[pipelineRunID] = t
+	}
+	return t
+}
+
+// recordStageProgress records one more completed unit of stage for
+// pipelineRunID and publishes the resulting snapshot as an
+// EventStageProgressDetail, so an SSE subscriber can render that stage's
+// progress bar without polling GetPipelineStatus.
+func (o *Orchestrator) recordStageProgress(pipelineRunID uint, stage string) {
+	snapshot := o.progressTrackerFor(pipelineRunID).RecordUnit(stage)
+	o.publishEvent(pipelineRunID, Event{
+		Type:          EventStageProgressDetail,
+		Stage:         stage,
+		StageProgress: &snapshot,
+	})
 }
 
 // StartPipeline initiates a new pipeline run
@@ -79,6 +354,29 @@ func (o *Orchestrator) StartPipeline(
 	if config.SearchEngine == "" {
 		config.SearchEngine = "duckduckgo"
 	}
+	if config.MaxParallelTopics == 0 {
+		config.MaxParallelTopics = 5
+	}
+	if config.MaxParallelEmbeds == 0 {
+		config.MaxParallelEmbeds = 4
+	}
+
+	// Validate the requested LLM provider (if any) up front by resolving it
+	// from the registry and closing it immediately, so a bad LLMProvider
+	// name or missing credentials fail the request at start time rather
+	// than surfacing later when a downstream stage tries to use it.
+	if config.LLMProvider != "" {
+		provider, err := llm.DefaultRegistry.New(context.Background(), config.LLMProvider, llm.Config{
+			Model:           config.LLMModel,
+			Temperature:     config.LLMTemperature,
+			MaxTokens:       config.LLMMaxTokens,
+			SafetyThreshold: config.LLMSafetyThreshold,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid llm_provider %q: %w", config.LLMProvider, err)
+		}
+		provider.Close()
+	}
 
 	// Marshal curriculum and config to JSON
 	inputDataBytes, err := json.Marshal(curriculum)
@@ -95,6 +393,27 @@ func (o *Orchestrator) StartPipeline(
 	var configDataMap map[string]interface{}
 	json.Unmarshal(configDataBytes, &configDataMap)
 
+	if o.jobQueue != nil {
+		pipelineRun := &models.PipelineRun{
+			CurriculumTitle: curriculum.Title,
+			Status:          StatusPending,
+			CurrentStage:    StageParse,
+			InputData:       inputDataMap,
+			Config:          configDataMap,
+			Progress:        0,
+		}
+
+		if err := o.db.Create(pipelineRun).Error; err != nil {
+			return nil, fmt.Errorf("failed to persist pipeline run: %w", err)
+		}
+
+		if _, err := o.jobQueue.Enqueue(pipelineRun.ID, nil, StageParse); err != nil {
+			return nil, fmt.Errorf("failed to enqueue parse stage: %w", err)
+		}
+
+		return pipelineRun, nil
+	}
+
 	o.runsMu.Lock()
 	runID := o.nextRunID
 	o.nextRunID++
@@ -113,66 +432,213 @@ func (o *Orchestrator) StartPipeline(
 	o.runs[runID] = pipelineRun
 	o.runsMu.Unlock()
 
-	// Start processing asynchronously
-	go o.processPipeline(pipelineRun.ID, curriculum, config)
+	if o.stateStore != nil {
+		if err := o.stateStore.PutRun(context.Background(), pipelineRun); err != nil {
+			return nil, fmt.Errorf("failed to persist pipeline run to state store: %w", err)
+		}
+	}
+
+	// Start processing asynchronously, under a context the run's runHandle
+	// can cancel (see CancelPipeline) instead of the goroutine running to
+	// completion regardless of what the caller wants.
+	ctx, cancel := context.WithCancel(context.Background())
+	o.activeMu.Lock()
+	o.active[pipelineRun.ID] = &runHandle{cancel: cancel, lease: time.Now()}
+	o.activeMu.Unlock()
+
+	go o.processPipeline(ctx, pipelineRun.ID, curriculum, config)
 
 	return pipelineRun, nil
 }
 
 // processPipeline executes the pipeline stages
 func (o *Orchestrator) processPipeline(
+	ctx context.Context,
 	pipelineRunID uint,
 	curriculum *models.Curriculum,
 	config models.PipelineConfig,
 ) {
+	defer func() {
+		o.activeMu.Lock()
+		delete(o.active, pipelineRunID)
+		o.activeMu.Unlock()
+	}()
+	defer o.flushLogs(pipelineRunID)
+
+	logs := o.logWriterFor(pipelineRunID)
+
+	leaseCtx, stopLease := context.WithCancel(ctx)
+	defer stopLease()
+	go o.renewLease(leaseCtx, pipelineRunID)
+
 	// Update status to processing
-	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageParse, 5, "")
+	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageParse, 5, nil, EventStageStarted)
 
 	// Stage 1: Parse curriculum (already done, just extract topics)
 	topics := o.parser.ExtractAllTopics(curriculum)
-	log.Printf("Pipeline %d: Extracted %d topics", pipelineRunID, len(topics))
+	logs.Printf(StageParse, "", LogInfo, "Extracted %d topics", len(topics))
 
 	// Create curriculum topic records
 	if err := o.createTopicRecords(pipelineRunID, curriculum, topics); err != nil {
-		o.updatePipelineStatus(pipelineRunID, StatusFailed, StageParse, 0, err.Error())
+		o.updatePipelineStatus(pipelineRunID, StatusFailed, StageParse, 0, err, EventPipelineFailed)
 		return
 	}
 
-	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageSearch, 15, "")
+	// Build this run's DAG: one search->normalize->chunk chain per topic.
+	// Topics don't depend on each other, so each stage below fans out across
+	// every topic at once instead of looping over them one at a time.
+	graph := NewPipelineGraph(topics, []string{StageSearch, StageNormalize, StageChunk})
+	o.graphsMu.Lock()
+	o.graphs[pipelineRunID] = graph
+	o.graphsMu.Unlock()
+	defer func() {
+		o.graphsMu.Lock()
+		delete(o.graphs, pipelineRunID)
+		o.graphsMu.Unlock()
+	}()
+
+	tracker := o.progressTrackerFor(pipelineRunID)
+	tracker.SetStageTotal(StageSearch, len(topics))
+	tracker.SetStageTotal(StageNormalize, len(topics))
+	tracker.SetStageTotal(StageChunk, len(topics))
+	defer func() {
+		o.progressMu.Lock()
+		delete(o.progress, pipelineRunID)
+		o.progressMu.Unlock()
+	}()
+
+	if o.failIfCancelled(ctx, pipelineRunID, StageSearch) {
+		return
+	}
+	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageSearch, 15, nil, EventStageStarted)
+
+	retryPolicy := retryPolicyFromConfig(config)
 
 	// Stage 2: Web Search (if enabled)
 	if config.WebSearchEnabled {
-		if err := o.enrichTopicsWithSearch(pipelineRunID, topics, config.SearchResultsPerTopic); err != nil {
-			o.updatePipelineStatus(pipelineRunID, StatusFailed, StageSearch, 0, err.Error())
+		if err := o.enrichTopicsWithSearch(ctx, pipelineRunID, graph, config.SearchResultsPerTopic, config.MaxParallelTopics, retryPolicy); err != nil {
+			o.updatePipelineStatus(pipelineRunID, StatusFailed, StageSearch, 0, err, EventPipelineFailed)
 			return
 		}
 	} else {
-		log.Printf("Pipeline %d: Web search disabled, using original content", pipelineRunID)
+		logs.Printf(StageSearch, "", LogInfo, "Web search disabled, using original content")
+		graph.CompleteStage(StageSearch)
 	}
+	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageSearch, 15, nil, EventStageCompleted)
 
-	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageNormalize, 40, "")
+	if o.failIfCancelled(ctx, pipelineRunID, StageNormalize) {
+		return
+	}
+	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageNormalize, 40, nil, EventStageStarted)
 
 	// Stage 3: Normalize content
-	if err := o.normalizeTopics(pipelineRunID, config.Normalize); err != nil {
-		o.updatePipelineStatus(pipelineRunID, StatusFailed, StageNormalize, 0, err.Error())
+	if err := o.normalizeTopics(ctx, pipelineRunID, graph, config.Normalize, config.MaxParallelTopics, retryPolicy); err != nil {
+		o.updatePipelineStatus(pipelineRunID, StatusFailed, StageNormalize, 0, err, EventPipelineFailed)
 		return
 	}
+	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageNormalize, 40, nil, EventStageCompleted)
 
-	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageChunk, 55, "")
+	if o.failIfCancelled(ctx, pipelineRunID, StageChunk) {
+		return
+	}
+	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageChunk, 55, nil, EventStageStarted)
 
 	// Stage 4: Chunk and embed
-	if err := o.chunkAndEmbedTopics(pipelineRunID, config); err != nil {
-		o.updatePipelineStatus(pipelineRunID, StatusFailed, StageChunk, 0, err.Error())
+	if err := o.chunkAndEmbedTopics(ctx, pipelineRunID, graph, config); err != nil {
+		o.updatePipelineStatus(pipelineRunID, StatusFailed, StageChunk, 0, err, EventPipelineFailed)
 		return
 	}
 
-	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageStore, 85, "")
+	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageChunk, 85, nil, EventStageCompleted)
+
+	if o.failIfCancelled(ctx, pipelineRunID, StageStore) {
+		return
+	}
+	o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageStore, 85, nil, EventStageStarted)
 
 	// Stage 5: Store documents (done in chunkAndEmbedTopics)
-	log.Printf("Pipeline %d: All topics processed successfully", pipelineRunID)
+	logs.Printf(StageStore, "", LogInfo, "All topics processed successfully")
 
 	// Mark as completed
-	o.updatePipelineStatus(pipelineRunID, StatusCompleted, StageStore, 100, "")
+	o.updatePipelineStatus(pipelineRunID, StatusCompleted, StageStore, 100, nil, EventPipelineCompleted)
+}
+
+// renewLease refreshes pipelineRunID's runHandle lease every
+// leaseRenewInterval until ctx is done, so StartLeaseJanitor can tell a
+// goroutine that's still alive and making progress apart from one that
+// panicked or deadlocked without unwinding its defers.
+func (o *Orchestrator) renewLease(ctx context.Context, pipelineRunID uint) {
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.activeMu.Lock()
+			handle, ok := o.active[pipelineRunID]
+			o.activeMu.Unlock()
+			if ok {
+				handle.renew()
+			}
+		}
+	}
+}
+
+// failIfCancelled marks pipelineRunID failed with errs.CodeCancelled and
+// returns true if ctx has been cancelled (see CancelPipeline), so
+// processPipeline can bail out between stages instead of starting a stage
+// whose work would just be thrown away.
+func (o *Orchestrator) failIfCancelled(ctx context.Context, pipelineRunID uint, stage string) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	o.updatePipelineStatus(pipelineRunID, StatusFailed, stage, 0,
+		errs.New(errs.CodeCancelled, "pipeline run cancelled"), EventPipelineFailed)
+	return true
+}
+
+// StartLeaseJanitor launches a background goroutine that, every interval,
+// fails any in-memory run whose lease hasn't been renewed in ttl - evidence
+// its processPipeline goroutine died without going through the normal
+// failure path (e.g. a panic that didn't unwind, or a process-wide
+// deadlock). It runs until the process exits; call once at startup.
+func (o *Orchestrator) StartLeaseJanitor(interval, ttl time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			o.reapExpiredLeases(ttl)
+		}
+	}()
+}
+
+func (o *Orchestrator) reapExpiredLeases(ttl time.Duration) {
+	o.activeMu.Lock()
+	var expired []uint
+	for pipelineRunID, handle := range o.active {
+		if handle.expired(ttl) {
+			expired = append(expired, pipelineRunID)
+			delete(o.active, pipelineRunID)
+		}
+	}
+	o.activeMu.Unlock()
+
+	for _, pipelineRunID := range expired {
+		log.Printf("Pipeline %d: lease expired, marking failed", pipelineRunID)
+
+		o.runsMu.RLock()
+		stage := ""
+		if run, ok := o.runs[pipelineRunID]; ok {
+			stage = run.CurrentStage
+		}
+		o.runsMu.RUnlock()
+
+		o.updatePipelineStatus(pipelineRunID, StatusFailed, stage, 0,
+			errs.New(errs.CodeCancelled, "pipeline run's lease expired (worker goroutine presumed dead)"), EventPipelineFailed)
+	}
 }
 
 // createTopicRecords creates database records for each topic
@@ -201,6 +667,13 @@ func (o *Orchestrator) createTopicRecords(
 			CreatedAt:       time.Now(),
 			UpdatedAt:       time.Now(),
 		}
+
+		if meta := o.parser.FindTopicMeta(curriculum, topicName); meta != nil {
+			curriculumTopic.Prerequisites = meta.Prerequisites
+			curriculumTopic.LearningObjectives = meta.LearningObjectives
+			curriculumTopic.Tags = meta.Tags
+		}
+
 		topics = append(topics, curriculumTopic)
 	}
 
@@ -209,56 +682,129 @@ func (o *Orchestrator) createTopicRecords(
 	return nil
 }
 
-// enrichTopicsWithSearch performs web search for each topic
+// enrichTopicsWithSearch performs web search for every topic in graph's
+// search stage, up to maxParallel at once (see runGraphStage). A topic's
+// search call is retried per policy (withRetry) before giving up; a topic
+// whose search still fails afterwards is logged and left unenriched rather
+// than failing the whole stage, since chunkAndEmbedTopics falls back to
+// OriginalContent.
 func (o *Orchestrator) enrichTopicsWithSearch(
+	ctx context.Context,
 	pipelineRunID uint,
-	topicNames []string,
-	maxResults int,
+	graph *PipelineGraph,
+	maxResults, maxParallel int,
+	policy RetryPolicy,
 ) error {
-	o.topicsMu.Lock()
-	topics, exists := o.topics[pipelineRunID]
-	o.topicsMu.Unlock()
-
-	if !exists {
-		return fmt.Errorf("topics not found for pipeline run %d", pipelineRunID)
+	byName, err := o.topicsByName(pipelineRunID)
+	if err != nil {
+		return err
 	}
 
-	for i, topic := range topics {
-		log.Printf("Pipeline %d: Searching for topic %d/%d: %s", pipelineRunID, i+1, len(topics), topic.TopicName)
+	logs := o.logWriterFor(pipelineRunID)
 
-		// Call embedding service to enrich topic
-		enrichedData, err := o.embeddingClient.EnrichTopic(topic.TopicName, maxResults)
+	runGraphStage(graph, StageSearch, maxParallel, func(topicName string) error {
+		topic := byName[topicName]
+		logs.Printf(StageSearch, topic.TopicName, LogInfo, "Searching for topic %s", topic.TopicName)
+
+		var content string
+		var results []interface{}
+		err := withRetry(ctx, policy, func() error {
+			var err error
+			content, results, err = o.enrichTopic(ctx, topic.TopicName, maxResults)
+			return err
+		})
 		if err != nil {
-			log.Printf("Warning: Failed to enrich topic %s: %v", topic.TopicName, err)
-			continue
+			logs.Printf(StageSearch, topic.TopicName, LogWarn, "Failed to enrich topic %s: %v", topic.TopicName, err)
+			o.recordStageProgress(pipelineRunID, StageSearch)
+			return nil
 		}
 
-		// Update topic with enriched content
-		// searchResultsJSON, _ := json.Marshal(enrichedData["results"])
-
 		o.topicsMu.Lock()
-		if content, ok := enrichedData["combined_content"].(string); ok {
-			topic.EnrichedContent = content
-		}
-		if results, ok := enrichedData["results"].(map[string]interface{}); ok {
-			topic.SearchResults = results
-		} else if results, ok := enrichedData["results"].([]interface{}); ok {
-			// If results is a list, wrap it in a map
-			topic.SearchResults = map[string]interface{}{"results": results}
-		}
+		topic.EnrichedContent = content
+		topic.SearchResults = map[string]interface{}{"results": results}
 		topic.Status = "searching"
 		topic.UpdatedAt = time.Now()
 		o.topicsMu.Unlock()
 
-		// Small delay to avoid rate limiting
-		time.Sleep(1 * time.Second)
-	}
+		o.recordStageProgress(pipelineRunID, StageSearch)
+		return nil
+	})
 
 	return nil
 }
 
+// topicsByName looks up pipelineRunID's in-memory topics and indexes them by
+// name, for stage functions that receive a PipelineGraph's topic names
+// rather than *models.CurriculumTopic values directly.
+func (o *Orchestrator) topicsByName(pipelineRunID uint) (map[string]*models.CurriculumTopic, error) {
+	o.topicsMu.RLock()
+	topics, exists := o.topics[pipelineRunID]
+	o.topicsMu.RUnlock()
+
+	if !exists {
+		return nil, errs.Newf(errs.CodeNotFound, "topics not found for pipeline run %d", pipelineRunID)
+	}
+
+	byName := make(map[string]*models.CurriculumTopic, len(topics))
+	for _, t := range topics {
+		byName[t.TopicName] = t
+	}
+	return byName, nil
+}
+
+// enrichTopic performs web search for a single topic and returns combined
+// content plus a normalized results slice, regardless of whether the search
+// ran through a configured SearchProvider or fell back to the embedding
+// microservice's own EnrichTopic endpoint.
+func (o *Orchestrator) enrichTopic(ctx context.Context, topicName string, maxResults int) (string, []interface{}, error) {
+	if o.searchProvider != nil {
+		hits, err := o.searchProvider.Search(ctx, topicName, maxResults)
+		if err != nil {
+			return "", nil, fmt.Errorf("search provider failed: %w", err)
+		}
+		content, results := buildEnrichment(hits)
+		return content, results, nil
+	}
+
+	enrichedData, err := o.embeddingClient.EnrichTopicContext(ctx, topicName, maxResults)
+	if err != nil {
+		return "", nil, err
+	}
+
+	content, _ := enrichedData["combined_content"].(string)
+	switch results := enrichedData["results"].(type) {
+	case []interface{}:
+		return content, results, nil
+	case map[string]interface{}:
+		if inner, ok := results["results"].([]interface{}); ok {
+			return content, inner, nil
+		}
+	}
+
+	return content, nil, nil
+}
+
 // ListPipelines lists all pipeline runs with pagination
 func (o *Orchestrator) ListPipelines(limit, offset int) ([]models.PipelineRun, int64, error) {
+	if o.stateStore != nil {
+		return o.stateStore.ListRuns(context.Background(), limit, offset)
+	}
+
+	if o.db != nil {
+		var runs []models.PipelineRun
+		var total int64
+
+		if err := o.db.Model(&models.PipelineRun{}).Count(&total).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to count pipeline runs: %w", err)
+		}
+
+		if err := o.db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to list pipeline runs: %w", err)
+		}
+
+		return runs, total, nil
+	}
+
 	o.runsMu.RLock()
 	defer o.runsMu.RUnlock()
 
@@ -267,11 +813,7 @@ func (o *Orchestrator) ListPipelines(limit, offset int) ([]models.PipelineRun, i
 		runs = append(runs, *run)
 	}
 
-	// Sort by CreatedAt DESC
-	// We need to implement sort, but for now let's just return them.
-	// Since map iteration is random, we should sort.
-	// But to save code, I'll skip sort or do simple bubble sort if needed.
-	// Let's just return as is for now or implement simple sort.
+	sort.Slice(runs, func(i, j int) bool { return runs[i].CreatedAt.After(runs[j].CreatedAt) })
 
 	total := int64(len(runs))
 
@@ -288,23 +830,28 @@ func (o *Orchestrator) ListPipelines(limit, offset int) ([]models.PipelineRun, i
 	return runs[offset:end], total, nil
 }
 
-// normalizeTopics normalizes the content for each topic
-func (o *Orchestrator) normalizeTopics(pipelineRunID uint, shouldNormalize bool) error {
+// normalizeTopics normalizes the content for every topic in graph's
+// normalize stage, up to maxParallel at once (see runGraphStage). The
+// NormalizeTextContext call is retried per policy (withRetry); a topic whose
+// normalization still fails afterwards falls back to its un-normalized
+// content rather than failing the whole stage.
+func (o *Orchestrator) normalizeTopics(ctx context.Context, pipelineRunID uint, graph *PipelineGraph, shouldNormalize bool, maxParallel int, policy RetryPolicy) error {
+	logs := o.logWriterFor(pipelineRunID)
+
 	if !shouldNormalize {
-		log.Printf("Pipeline %d: Normalization disabled", pipelineRunID)
+		logs.Printf(StageNormalize, "", LogInfo, "Normalization disabled")
+		graph.CompleteStage(StageNormalize)
 		return nil
 	}
 
-	o.topicsMu.RLock()
-	topics, exists := o.topics[pipelineRunID]
-	o.topicsMu.RUnlock()
-
-	if !exists {
-		return fmt.Errorf("topics not found for pipeline run %d", pipelineRunID)
+	byName, err := o.topicsByName(pipelineRunID)
+	if err != nil {
+		return err
 	}
 
-	for i, topic := range topics {
-		log.Printf("Pipeline %d: Normalizing topic %d/%d: %s", pipelineRunID, i+1, len(topics), topic.TopicName)
+	runGraphStage(graph, StageNormalize, maxParallel, func(topicName string) error {
+		topic := byName[topicName]
+		logs.Printf(StageNormalize, topic.TopicName, LogInfo, "Normalizing topic %s", topic.TopicName)
 
 		// Get the content to normalize (enriched if available, otherwise original)
 		content := topic.EnrichedContent
@@ -313,9 +860,14 @@ func (o *Orchestrator) normalizeTopics(pipelineRunID uint, shouldNormalize bool)
 		}
 
 		// Call embedding service to normalize
-		normalizedText, err := o.embeddingClient.NormalizeText(content, true)
+		var normalizedText string
+		err := withRetry(ctx, policy, func() error {
+			var err error
+			normalizedText, err = o.embeddingClient.NormalizeTextContext(ctx, content, true)
+			return err
+		})
 		if err != nil {
-			log.Printf("Warning: Failed to normalize topic %s: %v", topic.TopicName, err)
+			logs.Printf(StageNormalize, topic.TopicName, LogWarn, "Failed to normalize topic %s: %v", topic.TopicName, err)
 			normalizedText = content
 		}
 
@@ -323,145 +875,385 @@ func (o *Orchestrator) normalizeTopics(pipelineRunID uint, shouldNormalize bool)
 		topic.EnrichedContent = normalizedText
 		topic.UpdatedAt = time.Now()
 		o.topicsMu.Unlock()
+
+		o.recordStageProgress(pipelineRunID, StageNormalize)
+		return nil
+	})
+
+	return nil
+}
+
+// chunkAndEmbedTopics chunks, embeds, and stores documents for every topic in
+// graph's chunk stage, up to config.MaxParallelEmbeds at once (see
+// runGraphStage). Every embedding/Milvus write is retried per
+// retryPolicyFromConfig(config) (withRetry); a topic that still fails once
+// retries are exhausted is dead-lettered (Status = StatusDeadLetter,
+// LastError set) rather than failing the whole stage, since one topic's
+// persistently-bad content shouldn't block every other topic in a
+// long-running curriculum job - GetPipelineResults surfaces dead-lettered
+// topics, and RetryFailedTopics re-runs just those.
+func (o *Orchestrator) chunkAndEmbedTopics(ctx context.Context, pipelineRunID uint, graph *PipelineGraph, config models.PipelineConfig) error {
+	byName, err := o.topicsByName(pipelineRunID)
+	if err != nil {
+		return err
 	}
 
+	total := len(byName)
+	var completed int32
+
+	logs := o.logWriterFor(pipelineRunID)
+	policy := retryPolicyFromConfig(config)
+
+	runGraphStage(graph, StageChunk, config.MaxParallelEmbeds, func(topicName string) error {
+		topic := byName[topicName]
+		if err := o.chunkEmbedAndStoreTopic(ctx, pipelineRunID, topic, config, policy, logs); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			o.deadLetterTopic(pipelineRunID, topic, err, logs)
+			o.recordStageProgress(pipelineRunID, StageChunk)
+			return nil
+		}
+
+		o.recordStageProgress(pipelineRunID, StageChunk)
+
+		// Update progress
+		n := atomic.AddInt32(&completed, 1)
+		progress := 85 + int(float64(n)/float64(total)*10)
+		o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageStore, progress, nil, EventStageProgress)
+
+		return nil
+	})
+
 	return nil
 }
 
-// chunkAndEmbedTopics chunks, embeds, and stores documents for each topic
-func (o *Orchestrator) chunkAndEmbedTopics(pipelineRunID uint, config models.PipelineConfig) error {
-	o.topicsMu.RLock()
-	topics, exists := o.topics[pipelineRunID]
-	o.topicsMu.RUnlock()
+// chunkEmbedAndStoreTopic does the actual create-document/chunk/embed/upsert
+// work for a single topic, retrying each EmbeddingClient/vector.Store call
+// per policy. Split out of chunkAndEmbedTopics' closure so RetryFailedTopics
+// can call it again for just the topics that were dead-lettered, without
+// duplicating this logic.
+func (o *Orchestrator) chunkEmbedAndStoreTopic(
+	ctx context.Context,
+	pipelineRunID uint,
+	topic *models.CurriculumTopic,
+	config models.PipelineConfig,
+	policy RetryPolicy,
+	logs *LineWriter,
+) error {
+	logs.Printf(StageChunk, topic.TopicName, LogInfo, "Processing topic %s", topic.TopicName)
 
-	if !exists {
-		return fmt.Errorf("topics not found for pipeline run %d", pipelineRunID)
+	// Get final content (enriched if available, otherwise original)
+	content := topic.EnrichedContent
+	if content == "" {
+		content = topic.OriginalContent
 	}
 
-	for i, topic := range topics {
-		log.Printf("Pipeline %d: Processing topic %d/%d: %s", pipelineRunID, i+1, len(topics), topic.TopicName)
+	// Create document for this topic in Milvus
+	metadata := map[string]interface{}{
+		"pipeline_run_id": pipelineRunID,
+		"source":          "pipeline",
+	}
+	metadataBytes, _ := json.Marshal(metadata)
 
-		// Get final content (enriched if available, otherwise original)
-		content := topic.EnrichedContent
-		if content == "" {
-			content = topic.OriginalContent
-		}
+	milvusDoc := &vector.Document{
+		Title:    topic.TopicName,
+		Content:  content,
+		DocType:  "curriculum_topic",
+		Metadata: string(metadataBytes),
+	}
 
-		// Create document for this topic in Milvus
-		metadata := map[string]interface{}{
-			"pipeline_run_id": pipelineRunID,
-			"source":          "pipeline",
-		}
-		metadataBytes, _ := json.Marshal(metadata)
+	var docID int64
+	if err := withRetry(ctx, policy, func() error {
+		var err error
+		docID, err = o.store.CreateDocument(milvusDoc, config.TenantID)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to create document for %s: %w", topic.TopicName, err)
+	}
 
-		milvusDoc := &vector.Document{
-			Title:    topic.TopicName,
-			Content:  content,
-			DocType:  "curriculum_topic",
-			Metadata: string(metadataBytes),
-		}
+	// Chunk and embed the content
+	milvusChunks, err := o.chunkAndEmbed(ctx, content, config, policy)
+	if err != nil {
+		o.store.Delete(docID, config.TenantID)
+		return fmt.Errorf("failed to chunk content for %s: %w", topic.TopicName, err)
+	}
+	now := time.Now().Unix()
+	for j := range milvusChunks {
+		milvusChunks[j].DocumentID = docID
+		milvusChunks[j].DocType = milvusDoc.DocType
+		milvusChunks[j].CreatedAt = now
+	}
 
-		docID, err := o.milvusClient.CreateDocument(milvusDoc)
-		if err != nil {
-			return fmt.Errorf("failed to create document for %s: %w", topic.TopicName, err)
-		}
+	// Store in Milvus
+	if err := withRetry(ctx, policy, func() error {
+		return o.store.Upsert(milvusChunks, config.TenantID)
+	}); err != nil {
+		o.store.Delete(docID, config.TenantID)
+		return fmt.Errorf("failed to store chunks in Milvus: %w", err)
+	}
 
-		// Chunk the content
-		chunks, err := o.embeddingClient.ChunkText(content, config.ChunkSize)
-		if err != nil {
-			o.milvusClient.DeleteDocument(docID)
-			return fmt.Errorf("failed to chunk content for %s: %w", topic.TopicName, err)
-		}
+	// Update topic with document ID
+	o.topicsMu.Lock()
+	uintDocID := uint(docID)
+	topic.DocumentID = &uintDocID
+	topic.Status = StatusCompleted
+	topic.LastError = ""
+	topic.UpdatedAt = time.Now()
+	o.topicsMu.Unlock()
 
-		// Generate embeddings for all chunks
-		embeddings, err := o.embeddingClient.GetEmbeddings(chunks)
-		if err != nil {
-			o.milvusClient.DeleteDocument(docID)
-			return fmt.Errorf("failed to generate embeddings for %s: %w", topic.TopicName, err)
-		}
+	o.publishEvent(pipelineRunID, Event{
+		Type:      EventChunkEmbedded,
+		Stage:     StageChunk,
+		Topic:     topic.TopicName,
+		ItemIndex: len(milvusChunks),
+		ItemTotal: len(milvusChunks),
+	})
+	o.publishEvent(pipelineRunID, Event{
+		Type:  EventDocumentPersisted,
+		Stage: StageStore,
+		Topic: topic.TopicName,
+	})
 
-		// Create chunks with embeddings
-		var milvusChunks []vector.Chunk
-		for j, chunk := range chunks {
-			milvusChunks = append(milvusChunks, vector.Chunk{
-				DocumentID: docID,
-				ChunkIndex: int64(j),
-				ChunkText:  chunk,
-				Embedding:  embeddings[j],
-			})
+	return nil
+}
+
+// deadLetterTopic marks topic dead-lettered after chunkEmbedAndStoreTopic
+// exhausted its retries, recording cause as LastError (surfaced via
+// GetPipelineResults) and logging it to the run's LineWriter, so one
+// persistently-failing topic doesn't abort chunkAndEmbedTopics for every
+// other topic in the run.
+func (o *Orchestrator) deadLetterTopic(pipelineRunID uint, topic *models.CurriculumTopic, cause error, logs *LineWriter) {
+	logs.Printf(StageChunk, topic.TopicName, LogError, "Topic %s exhausted retries, dead-lettering: %v", topic.TopicName, cause)
+
+	o.topicsMu.Lock()
+	topic.Status = StatusDeadLetter
+	topic.LastError = cause.Error()
+	topic.UpdatedAt = time.Now()
+	o.topicsMu.Unlock()
+}
+
+// RetryFailedTopics re-runs chunkEmbedAndStoreTopic for every topic in
+// pipelineRunID's run currently dead-lettered (see deadLetterTopic),
+// without re-running the rest of the pipeline. Only supported for in-memory
+// runs - the durable job-queue path already retries/dead-letters at the job
+// level (see WorkerPool, HandleJobDeadLetter) and resumes via ResumePipeline
+// instead.
+func (o *Orchestrator) RetryFailedTopics(pipelineRunID uint) error {
+	if o.jobQueue != nil {
+		return errs.New(errs.CodeValidation, "RetryFailedTopics only applies to in-memory pipeline runs; use ResumePipeline for durable runs")
+	}
+
+	o.runsMu.RLock()
+	run, exists := o.runs[pipelineRunID]
+	o.runsMu.RUnlock()
+	if !exists {
+		return errs.Newf(errs.CodeNotFound, "pipeline run %d not found", pipelineRunID)
+	}
+
+	o.topicsMu.RLock()
+	var deadLettered []*models.CurriculumTopic
+	for _, topic := range o.topics[pipelineRunID] {
+		if topic.Status == StatusDeadLetter {
+			deadLettered = append(deadLettered, topic)
 		}
+	}
+	o.topicsMu.RUnlock()
 
-		// Store in Milvus
-		if err := o.milvusClient.AddChunks(milvusChunks); err != nil {
-			o.milvusClient.DeleteDocument(docID)
-			return fmt.Errorf("failed to store chunks in Milvus: %w", err)
+	if len(deadLettered) == 0 {
+		return errs.Newf(errs.CodeValidation, "pipeline run %d has no dead-lettered topics to retry", pipelineRunID)
+	}
+
+	config := configFromRun(run)
+	policy := retryPolicyFromConfig(config)
+	logs := o.logWriterFor(pipelineRunID)
+
+	ctx := context.Background()
+	go func() {
+		defer o.flushLogs(pipelineRunID)
+		for _, topic := range deadLettered {
+			if err := o.chunkEmbedAndStoreTopic(ctx, pipelineRunID, topic, config, policy, logs); err != nil {
+				o.deadLetterTopic(pipelineRunID, topic, err, logs)
+			}
 		}
+	}()
 
-		// Update topic with document ID
-		o.topicsMu.Lock()
-		uintDocID := uint(docID)
-		topic.DocumentID = &uintDocID
-		topic.Status = StatusCompleted
-		topic.UpdatedAt = time.Now()
-		o.topicsMu.Unlock()
+	return nil
+}
 
-		// Update progress
-		progress := 85 + int(float64(i+1)/float64(len(topics))*10)
-		o.updatePipelineStatus(pipelineRunID, StatusProcessing, StageStore, progress, "")
+// chunkAndEmbed splits content with the strategy named in config.ChunkStrategy
+// (falling back to chunking.DefaultStrategy) and embeds every resulting
+// piece, returning chunks with DocumentID left unset for the caller to fill
+// in. Used by both the in-memory orchestrator and the job-queue worker path
+// (see processChunkJob) so they chunk identically. ctx only governs the
+// final embedding call here; chunking.Options.Embedder (used by strategies
+// like semantic chunking) goes through the chunking.Embedder interface,
+// which doesn't take a context, so a mid-chunking retryable failure there
+// isn't covered by policy the way the final GetEmbeddingsContext call is.
+func (o *Orchestrator) chunkAndEmbed(ctx context.Context, content string, config models.PipelineConfig, policy RetryPolicy) ([]vector.Chunk, error) {
+	chunker, ok := chunking.Get(config.ChunkStrategy)
+	if !ok {
+		return nil, fmt.Errorf("unknown chunk strategy %q", config.ChunkStrategy)
 	}
 
-	return nil
+	docChunks, err := chunker.Chunk(content, chunking.Options{
+		MaxSize:  config.ChunkSize,
+		Overlap:  config.ChunkOverlap,
+		Embedder: o.embeddingClient,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk content: %w", err)
+	}
+
+	texts := make([]string, len(docChunks))
+	for i, dc := range docChunks {
+		texts[i] = dc.Text
+	}
+
+	var embeddings [][]float32
+	if err := withRetry(ctx, policy, func() error {
+		var err error
+		embeddings, err = o.embeddingClient.GetEmbeddingsContext(ctx, texts)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+
+	chunks := make([]vector.Chunk, len(docChunks))
+	for i, dc := range docChunks {
+		metadataBytes, _ := json.Marshal(map[string]interface{}{
+			"start":        dc.Start,
+			"end":          dc.End,
+			"heading_path": dc.HeadingPath,
+		})
+		chunks[i] = vector.Chunk{
+			ChunkIndex: int64(i),
+			ChunkText:  dc.Text,
+			Embedding:  embeddings[i],
+			Metadata:   string(metadataBytes),
+		}
+	}
+
+	return chunks, nil
 }
 
-// updatePipelineStatus updates the pipeline run status
+// updatePipelineStatus updates the pipeline run status and, unless eventType
+// is the zero value, publishes a matching Event to o.events so SSE
+// subscribers see the same transition. stepErr is the stage failure causing
+// this update, or nil on success; its errs.Code (if any) is attached to the
+// published event.
 func (o *Orchestrator) updatePipelineStatus(
 	pipelineRunID uint,
 	status string,
 	stage string,
 	progress int,
-	errorMessage string,
+	stepErr error,
+	eventType EventType,
 ) {
-	o.runsMu.Lock()
-	defer o.runsMu.Unlock()
+	var errorMessage string
+	if stepErr != nil {
+		errorMessage = stepErr.Error()
+	}
 
-	run, exists := o.runs[pipelineRunID]
-	if !exists {
-		log.Printf("Error updating pipeline status: run %d not found", pipelineRunID)
-		return
+	apply := func(run *models.PipelineRun) error {
+		run.Status = status
+		run.CurrentStage = stage
+		run.Progress = progress
+		run.UpdatedAt = time.Now()
+		if errorMessage != "" {
+			run.ErrorMessage = errorMessage
+		}
+		return nil
 	}
 
-	run.Status = status
-	run.CurrentStage = stage
-	run.Progress = progress
-	run.UpdatedAt = time.Now()
+	if o.stateStore != nil {
+		// Parallel workers in different stages can race to report progress
+		// on the same run (see runGraphStage), so this goes through a CAS
+		// update instead of a blind overwrite.
+		if err := o.stateStore.CompareAndSwapRun(context.Background(), pipelineRunID, apply); err != nil {
+			log.Printf("Error updating pipeline status via state store: %v", err)
+			return
+		}
+	} else {
+		o.runsMu.Lock()
+		run, exists := o.runs[pipelineRunID]
+		if !exists {
+			o.runsMu.Unlock()
+			log.Printf("Error updating pipeline status: run %d not found", pipelineRunID)
+			return
+		}
+		apply(run)
+		o.runsMu.Unlock()
+	}
 
-	if errorMessage != "" {
-		run.ErrorMessage = errorMessage
+	if eventType == "" {
+		return
 	}
+
+	ev := Event{Type: eventType, Stage: stage, Progress: progress, Message: errorMessage}
+	if e, ok := errs.As(stepErr); ok {
+		ev.Code = e.Code
+	}
+	o.publishEvent(pipelineRunID, ev)
 }
 
 // GetPipelineStatus retrieves the current status of a pipeline run
 func (o *Orchestrator) GetPipelineStatus(pipelineRunID uint) (*models.PipelineStatusResponse, error) {
-	o.runsMu.RLock()
-	run, exists := o.runs[pipelineRunID]
-	o.runsMu.RUnlock()
+	var run *models.PipelineRun
 
-	if !exists {
-		return nil, fmt.Errorf("pipeline run not found")
+	switch {
+	case o.db != nil:
+		var dbRun models.PipelineRun
+		if err := o.db.First(&dbRun, pipelineRunID).Error; err != nil {
+			return nil, errs.Newf(errs.CodeNotFound, "pipeline run %d not found", pipelineRunID)
+		}
+		run = &dbRun
+	case o.stateStore != nil:
+		stateRun, err := o.stateStore.GetRun(context.Background(), pipelineRunID)
+		if err != nil {
+			return nil, err
+		}
+		run = stateRun
+	default:
+		o.runsMu.RLock()
+		memRun, exists := o.runs[pipelineRunID]
+		o.runsMu.RUnlock()
+
+		if !exists {
+			return nil, errs.Newf(errs.CodeNotFound, "pipeline run %d not found", pipelineRunID)
+		}
+		run = memRun
 	}
 
 	// Build stages map
 	stages := o.buildStagesMap(run)
 
+	var topology *models.PipelineTopology
+	o.graphsMu.RLock()
+	if graph, ok := o.graphs[pipelineRunID]; ok {
+		snapshot := graph.Snapshot()
+		topology = &snapshot
+	}
+	o.graphsMu.RUnlock()
+
+	var stageProgress map[string]models.StageProgress
+	o.progressMu.RLock()
+	if tracker, ok := o.progress[pipelineRunID]; ok {
+		stageProgress = tracker.Snapshot()
+	}
+	o.progressMu.RUnlock()
+
 	return &models.PipelineStatusResponse{
-		ID:           run.ID,
-		Status:       run.Status,
-		CurrentStage: run.CurrentStage,
-		Progress:     run.Progress,
-		Stages:       stages,
-		ErrorMessage: run.ErrorMessage,
-		CreatedAt:    run.CreatedAt,
-		UpdatedAt:    run.UpdatedAt,
+		ID:            run.ID,
+		Status:        run.Status,
+		CurrentStage:  run.CurrentStage,
+		Progress:      run.Progress,
+		Stages:        stages,
+		ErrorMessage:  run.ErrorMessage,
+		CreatedAt:     run.CreatedAt,
+		UpdatedAt:     run.UpdatedAt,
+		Topology:      topology,
+		StageProgress: stageProgress,
 	}, nil
 }
 
@@ -505,34 +1297,55 @@ func (o *Orchestrator) buildStagesMap(pipelineRun *models.PipelineRun) map[strin
 
 // GetPipelineResults retrieves the results of a completed pipeline run
 func (o *Orchestrator) GetPipelineResults(pipelineRunID uint) (*models.PipelineResultsResponse, error) {
-	o.runsMu.RLock()
-	run, exists := o.runs[pipelineRunID]
-	o.runsMu.RUnlock()
+	var run *models.PipelineRun
+	var topicValues []models.CurriculumTopic
 
-	if !exists {
-		return nil, fmt.Errorf("pipeline run not found")
-	}
+	if o.db != nil {
+		var dbRun models.PipelineRun
+		if err := o.db.First(&dbRun, pipelineRunID).Error; err != nil {
+			return nil, errs.Newf(errs.CodeNotFound, "pipeline run %d not found", pipelineRunID)
+		}
+		run = &dbRun
 
-	o.topicsMu.RLock()
-	topics := o.topics[pipelineRunID]
-	o.topicsMu.RUnlock()
+		if err := o.db.Where("pipeline_run_id = ?", pipelineRunID).Order("id ASC").Find(&topicValues).Error; err != nil {
+			return nil, fmt.Errorf("failed to load pipeline topics: %w", err)
+		}
+	} else {
+		o.runsMu.RLock()
+		memRun, exists := o.runs[pipelineRunID]
+		o.runsMu.RUnlock()
 
-	// Convert to value slice for response
-	var topicValues []models.CurriculumTopic
-	for _, t := range topics {
-		topicValues = append(topicValues, *t)
+		if !exists {
+			return nil, errs.Newf(errs.CodeNotFound, "pipeline run %d not found", pipelineRunID)
+		}
+		run = memRun
+
+		o.topicsMu.RLock()
+		topics := o.topics[pipelineRunID]
+		o.topicsMu.RUnlock()
+
+		for _, t := range topics {
+			topicValues = append(topicValues, *t)
+		}
+	}
+
+	topics := make([]*models.CurriculumTopic, len(topicValues))
+	for i := range topicValues {
+		topics[i] = &topicValues[i]
 	}
 
 	// We need to fetch documents from Milvus that match this pipeline run
 	// Since we don't have a direct "GetDocumentsByMetadata" in our simple MilvusClient,
 	// and we stored DocumentID in topics, we can fetch by ID.
 
+	tenantID := configFromRun(run).TenantID
+
 	var documents []models.Document
 	totalChunks := 0
 
 	for _, topic := range topics {
 		if topic.DocumentID != nil {
-			milvusDoc, err := o.milvusClient.GetDocument(int64(*topic.DocumentID))
+			milvusDoc, err := o.store.GetDocument(int64(*topic.DocumentID), tenantID)
 			if err != nil {
 				continue
 			}
@@ -573,21 +1386,55 @@ func (o *Orchestrator) GetPipelineResults(pipelineRunID uint) (*models.PipelineR
 
 // CancelPipeline cancels a running pipeline
 func (o *Orchestrator) CancelPipeline(pipelineRunID uint) error {
-	o.runsMu.Lock()
-	defer o.runsMu.Unlock()
+	if o.db != nil {
+		var run models.PipelineRun
+		if err := o.db.First(&run, pipelineRunID).Error; err != nil {
+			return errs.Newf(errs.CodeNotFound, "pipeline run %d not found", pipelineRunID)
+		}
+
+		if run.Status == StatusCompleted || run.Status == StatusFailed {
+			return errs.Newf(errs.CodeValidation, "cannot cancel pipeline in %s status", run.Status)
+		}
 
+		if err := o.jobQueue.CancelRun(pipelineRunID); err != nil {
+			return fmt.Errorf("failed to cancel queued jobs: %w", err)
+		}
+
+		return o.db.Model(&models.PipelineRun{}).Where("id = ?", pipelineRunID).Updates(map[string]interface{}{
+			"status":        StatusFailed,
+			"error_message": "Pipeline cancelled by user",
+			"updated_at":    time.Now(),
+		}).Error
+	}
+
+	o.runsMu.RLock()
 	run, exists := o.runs[pipelineRunID]
+	var status string
+	if exists {
+		status = run.Status
+	}
+	o.runsMu.RUnlock()
+
 	if !exists {
-		return fmt.Errorf("pipeline run not found")
+		return errs.Newf(errs.CodeNotFound, "pipeline run %d not found", pipelineRunID)
 	}
 
-	if run.Status == StatusCompleted || run.Status == StatusFailed {
-		return fmt.Errorf("cannot cancel pipeline in %s status", run.Status)
+	if status == StatusCompleted || status == StatusFailed {
+		return errs.Newf(errs.CodeValidation, "cannot cancel pipeline in %s status", status)
 	}
 
-	run.Status = StatusFailed
-	run.ErrorMessage = "Pipeline cancelled by user"
-	run.UpdatedAt = time.Now()
+	// Cancel the run's context rather than flipping its status directly:
+	// processPipeline's goroutine observes ctx.Done() at the next stage
+	// boundary (see failIfCancelled) and aborts any in-flight EmbeddingClient
+	// call immediately, then transitions the run to failed itself so there's
+	// one place that does it instead of two racing writers.
+	o.activeMu.Lock()
+	handle, ok := o.active[pipelineRunID]
+	o.activeMu.Unlock()
+	if !ok {
+		return errs.Newf(errs.CodeNotFound, "pipeline run %d is not currently running", pipelineRunID)
+	}
 
+	handle.cancel()
 	return nil
 }
@@ -0,0 +1,427 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/vector"
+)
+
+// ProcessJob executes a single persisted pipeline job (one stage for one run
+// or topic) and, on success, enqueues whatever job comes next. It is called
+// by a WorkerPool and requires SetJobQueue to have been configured.
+func (o *Orchestrator) ProcessJob(job *models.PipelineJob) error {
+	var run models.PipelineRun
+	if err := o.db.First(&run, job.PipelineRunID).Error; err != nil {
+		return errs.Wrapf(errs.CodeNotFound, err, "pipeline run %d not found", job.PipelineRunID)
+	}
+
+	if run.Status == StatusFailed {
+		// Run was cancelled or already failed; drop any in-flight work for it.
+		return nil
+	}
+
+	switch job.Stage {
+	case StageParse:
+		return o.processParseJob(&run)
+	case StageSearch:
+		return o.processSearchJob(&run, job)
+	case StageNormalize:
+		return o.processNormalizeJob(&run, job)
+	case StageChunk:
+		return o.processChunkJob(&run, job)
+	default:
+		return errs.Newf(errs.CodeValidation, "unknown pipeline stage %q", job.Stage)
+	}
+}
+
+// HandleJobDeadLetter marks the work a job represents as permanently failed
+// once it has exhausted its retries, so the run doesn't hang forever waiting
+// on a topic that will never complete.
+func (o *Orchestrator) HandleJobDeadLetter(job *models.PipelineJob, jobErr error) error {
+	if job.TopicID == nil {
+		if err := o.db.Model(&models.PipelineRun{}).Where("id = ?", job.PipelineRunID).Updates(map[string]interface{}{
+			"status":        StatusFailed,
+			"error_message": jobErr.Error(),
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		ev := Event{Type: EventPipelineFailed, Stage: job.Stage, Message: jobErr.Error()}
+		if e, ok := errs.As(jobErr); ok {
+			ev.Code = e.Code
+		}
+		o.publishEvent(job.PipelineRunID, ev)
+		return nil
+	}
+
+	if err := o.db.Model(&models.CurriculumTopic{}).Where("id = ?", *job.TopicID).Updates(map[string]interface{}{
+		"status":     StatusFailed,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+
+	return o.finalizeRunIfDone(job.PipelineRunID)
+}
+
+// RecoverIncompleteRuns re-enqueues a job for every run left pending or
+// processing by a crashed process, so ingestion resumes on boot instead of
+// hanging indefinitely. Call once at startup, after RecoverStuckJobs.
+func (o *Orchestrator) RecoverIncompleteRuns() error {
+	var runs []models.PipelineRun
+	if err := o.db.Where("status IN ?", []string{StatusPending, StatusProcessing}).Find(&runs).Error; err != nil {
+		return fmt.Errorf("failed to load incomplete pipeline runs: %w", err)
+	}
+
+	for _, run := range runs {
+		if err := o.resumeRun(&run); err != nil {
+			log.Printf("Warning: failed to resume pipeline run %d during recovery: %v", run.ID, err)
+		}
+	}
+
+	log.Printf("Recovered %d incomplete pipeline run(s)", len(runs))
+	return nil
+}
+
+// ResumePipeline re-enqueues whatever work remains for a single pending or
+// processing run: topics already completed or failed (checkpointed in
+// CurriculumTopic.Status/DocumentID - see processChunkJob) are skipped, and
+// every other topic is re-enqueued at the run's CurrentStage; a topic's own
+// progress through earlier stages is not recorded. Unlike RecoverIncompleteRuns,
+// which sweeps every incomplete run once at boot, this targets one run and
+// can be called at any time - e.g. from an admin endpoint, after confirming
+// via RecoverExpiredLeases/the job list that its worker died mid-run.
+func (o *Orchestrator) ResumePipeline(pipelineRunID uint) error {
+	if o.jobQueue == nil {
+		return errs.New(errs.CodeValidation, "ResumePipeline requires a durable job queue")
+	}
+
+	var run models.PipelineRun
+	if err := o.db.First(&run, pipelineRunID).Error; err != nil {
+		return errs.Wrapf(errs.CodeNotFound, err, "pipeline run %d not found", pipelineRunID)
+	}
+
+	if run.Status != StatusPending && run.Status != StatusProcessing {
+		return errs.Newf(errs.CodeValidation, "cannot resume pipeline in %s status", run.Status)
+	}
+
+	return o.resumeRun(&run)
+}
+
+// resumeRun re-enqueues run's outstanding work: a fresh parse job if it has
+// no topics yet, otherwise one job per topic that hasn't reached a terminal
+// status, at the run's CurrentStage.
+func (o *Orchestrator) resumeRun(run *models.PipelineRun) error {
+	var topics []models.CurriculumTopic
+	if err := o.db.Where("pipeline_run_id = ?", run.ID).Find(&topics).Error; err != nil {
+		return fmt.Errorf("failed to load topics for pipeline run %d: %w", run.ID, err)
+	}
+
+	if len(topics) == 0 {
+		// Parse never completed (or produced no topics yet); redo it.
+		if _, err := o.jobQueue.Enqueue(run.ID, nil, StageParse); err != nil {
+			return fmt.Errorf("failed to re-enqueue parse stage for pipeline run %d: %w", run.ID, err)
+		}
+		return nil
+	}
+
+	for _, topic := range topics {
+		if topic.Status == StatusCompleted || topic.Status == StatusFailed {
+			continue
+		}
+
+		topicID := topic.ID
+		if _, err := o.jobQueue.Enqueue(run.ID, &topicID, run.CurrentStage); err != nil {
+			log.Printf("Warning: failed to re-enqueue %s stage for topic %d: %v", run.CurrentStage, topicID, err)
+		}
+	}
+
+	return nil
+}
+
+func (o *Orchestrator) processParseJob(run *models.PipelineRun) error {
+	curriculum, err := curriculumFromRun(run)
+	if err != nil {
+		return err
+	}
+	config := configFromRun(run)
+
+	topicNames := o.parser.ExtractAllTopics(curriculum)
+	log.Printf("Pipeline %d: Extracted %d topics", run.ID, len(topicNames))
+
+	if len(topicNames) == 0 {
+		return o.updateRunProgress(run.ID, StatusCompleted, StageStore, 100, EventPipelineCompleted)
+	}
+
+	nextStage := StageSearch
+	if !config.WebSearchEnabled {
+		nextStage = StageNormalize
+		if !config.Normalize {
+			nextStage = StageChunk
+		}
+	}
+
+	for _, topicName := range topicNames {
+		topic := &models.CurriculumTopic{
+			PipelineRunID:   run.ID,
+			TopicName:       topicName,
+			OriginalContent: o.parser.GenerateTopicContext(curriculum, topicName),
+			Status:          StatusPending,
+		}
+
+		if meta := o.parser.FindTopicMeta(curriculum, topicName); meta != nil {
+			topic.Prerequisites = meta.Prerequisites
+			topic.LearningObjectives = meta.LearningObjectives
+			topic.Tags = meta.Tags
+		}
+
+		if err := o.db.Create(topic).Error; err != nil {
+			return fmt.Errorf("failed to persist topic %s: %w", topicName, err)
+		}
+
+		if _, err := o.jobQueue.Enqueue(run.ID, &topic.ID, nextStage); err != nil {
+			return fmt.Errorf("failed to enqueue %s stage for topic %s: %w", nextStage, topicName, err)
+		}
+	}
+
+	return o.updateRunProgress(run.ID, StatusProcessing, nextStage, 15, EventStageStarted)
+}
+
+func (o *Orchestrator) processSearchJob(run *models.PipelineRun, job *models.PipelineJob) error {
+	var topic models.CurriculumTopic
+	if err := o.db.First(&topic, *job.TopicID).Error; err != nil {
+		return errs.Wrapf(errs.CodeNotFound, err, "topic %d not found", *job.TopicID)
+	}
+
+	config := configFromRun(run)
+	maxResults := config.SearchResultsPerTopic
+	if maxResults == 0 {
+		maxResults = 5
+	}
+
+	content, results, err := o.enrichTopic(context.Background(), topic.TopicName, maxResults)
+	if err != nil {
+		log.Printf("Warning: Failed to enrich topic %s: %v", topic.TopicName, err)
+	} else {
+		topic.EnrichedContent = content
+		topic.SearchResults = map[string]interface{}{"results": results}
+		topic.Status = "searching"
+
+		if err := o.db.Save(&topic).Error; err != nil {
+			return fmt.Errorf("failed to save search results for topic %s: %w", topic.TopicName, err)
+		}
+	}
+
+	nextStage := StageChunk
+	if config.Normalize {
+		nextStage = StageNormalize
+	}
+
+	if _, err := o.jobQueue.Enqueue(run.ID, &topic.ID, nextStage); err != nil {
+		return fmt.Errorf("failed to enqueue %s stage for topic %s: %w", nextStage, topic.TopicName, err)
+	}
+
+	return nil
+}
+
+func (o *Orchestrator) processNormalizeJob(run *models.PipelineRun, job *models.PipelineJob) error {
+	var topic models.CurriculumTopic
+	if err := o.db.First(&topic, *job.TopicID).Error; err != nil {
+		return errs.Wrapf(errs.CodeNotFound, err, "topic %d not found", *job.TopicID)
+	}
+
+	content := topic.EnrichedContent
+	if content == "" {
+		content = topic.OriginalContent
+	}
+
+	normalizedText, err := o.embeddingClient.NormalizeText(content, true)
+	if err != nil {
+		log.Printf("Warning: Failed to normalize topic %s: %v", topic.TopicName, err)
+		normalizedText = content
+	}
+
+	topic.EnrichedContent = normalizedText
+	if err := o.db.Save(&topic).Error; err != nil {
+		return fmt.Errorf("failed to save normalized content for topic %s: %w", topic.TopicName, err)
+	}
+
+	if _, err := o.jobQueue.Enqueue(run.ID, &topic.ID, StageChunk); err != nil {
+		return fmt.Errorf("failed to enqueue chunk stage for topic %s: %w", topic.TopicName, err)
+	}
+
+	return nil
+}
+
+// processChunkJob chunks, embeds, and stores the topic's content. It covers
+// what the stage constants separate into chunk/embed/store, matching the
+// in-memory orchestrator's chunkAndEmbedTopics.
+func (o *Orchestrator) processChunkJob(run *models.PipelineRun, job *models.PipelineJob) error {
+	var topic models.CurriculumTopic
+	if err := o.db.First(&topic, *job.TopicID).Error; err != nil {
+		return errs.Wrapf(errs.CodeNotFound, err, "topic %d not found", *job.TopicID)
+	}
+
+	config := configFromRun(run)
+	policy := retryPolicyFromConfig(config)
+	ctx := context.Background()
+
+	content := topic.EnrichedContent
+	if content == "" {
+		content = topic.OriginalContent
+	}
+
+	metadata := map[string]interface{}{
+		"pipeline_run_id": run.ID,
+		"source":          "pipeline",
+	}
+	metadataBytes, _ := json.Marshal(metadata)
+
+	doc := &vector.Document{
+		Title:    topic.TopicName,
+		Content:  content,
+		DocType:  "curriculum_topic",
+		Metadata: string(metadataBytes),
+	}
+
+	var docID int64
+	if err := withRetry(ctx, policy, func() error {
+		var err error
+		docID, err = o.store.CreateDocument(doc, config.TenantID)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to create document for %s: %w", topic.TopicName, err)
+	}
+
+	storeChunks, err := o.chunkAndEmbed(ctx, content, config, policy)
+	if err != nil {
+		o.store.Delete(docID, config.TenantID)
+		return fmt.Errorf("failed to chunk content for %s: %w", topic.TopicName, err)
+	}
+	now := time.Now().Unix()
+	for i := range storeChunks {
+		storeChunks[i].DocumentID = docID
+		storeChunks[i].DocType = doc.DocType
+		storeChunks[i].CreatedAt = now
+	}
+
+	if err := withRetry(ctx, policy, func() error {
+		return o.store.Upsert(storeChunks, config.TenantID)
+	}); err != nil {
+		o.store.Delete(docID, config.TenantID)
+		return fmt.Errorf("failed to store chunks for %s: %w", topic.TopicName, err)
+	}
+
+	docIDUint := uint(docID)
+	topic.DocumentID = &docIDUint
+	topic.Status = StatusCompleted
+	if err := o.db.Save(&topic).Error; err != nil {
+		return fmt.Errorf("failed to save completed topic %s: %w", topic.TopicName, err)
+	}
+
+	o.publishEvent(run.ID, Event{
+		Type:      EventChunkEmbedded,
+		Stage:     StageChunk,
+		Topic:     topic.TopicName,
+		ItemIndex: len(storeChunks),
+		ItemTotal: len(storeChunks),
+	})
+	o.publishEvent(run.ID, Event{
+		Type:  EventDocumentPersisted,
+		Stage: StageStore,
+		Topic: topic.TopicName,
+	})
+
+	return o.finalizeRunIfDone(run.ID)
+}
+
+// finalizeRunIfDone marks the run completed (or failed, if any topic
+// dead-lettered) once every one of its topics has reached a terminal state,
+// and otherwise just advances its progress percentage.
+func (o *Orchestrator) finalizeRunIfDone(pipelineRunID uint) error {
+	var total, completed, failed int64
+
+	if err := o.db.Model(&models.CurriculumTopic{}).Where("pipeline_run_id = ?", pipelineRunID).Count(&total).Error; err != nil {
+		return err
+	}
+	if err := o.db.Model(&models.CurriculumTopic{}).
+		Where("pipeline_run_id = ? AND status = ?", pipelineRunID, StatusCompleted).
+		Count(&completed).Error; err != nil {
+		return err
+	}
+	if err := o.db.Model(&models.CurriculumTopic{}).
+		Where("pipeline_run_id = ? AND status = ?", pipelineRunID, StatusFailed).
+		Count(&failed).Error; err != nil {
+		return err
+	}
+
+	done := completed + failed
+	if done < total {
+		progress := 55 + int(float64(done)/float64(total)*45)
+		return o.updateRunProgress(pipelineRunID, StatusProcessing, StageChunk, progress, EventStageProgress)
+	}
+
+	status := StatusCompleted
+	eventType := EventPipelineCompleted
+	if failed > 0 {
+		status = StatusFailed
+		eventType = EventPipelineFailed
+	}
+
+	log.Printf("Pipeline %d: all %d topics finished (status=%s)", pipelineRunID, total, status)
+
+	return o.updateRunProgress(pipelineRunID, status, StageStore, 100, eventType)
+}
+
+// updateRunProgress updates the run's stage/progress and, unless eventType
+// is the zero value, publishes a matching Event so SSE subscribers see the
+// same transition as the in-memory orchestrator's updatePipelineStatus does.
+func (o *Orchestrator) updateRunProgress(pipelineRunID uint, status, stage string, progress int, eventType EventType) error {
+	if err := o.db.Model(&models.PipelineRun{}).Where("id = ?", pipelineRunID).Updates(map[string]interface{}{
+		"status":        status,
+		"current_stage": stage,
+		"progress":      progress,
+		"updated_at":    time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+
+	if eventType == "" {
+		return nil
+	}
+
+	ev := Event{Type: eventType, Stage: stage, Progress: progress}
+	if status == StatusFailed {
+		ev.Message = "one or more topics failed"
+	}
+	o.publishEvent(pipelineRunID, ev)
+	return nil
+}
+
+func configFromRun(run *models.PipelineRun) models.PipelineConfig {
+	var config models.PipelineConfig
+	configBytes, _ := json.Marshal(run.Config)
+	json.Unmarshal(configBytes, &config)
+	return config
+}
+
+func curriculumFromRun(run *models.PipelineRun) (*models.Curriculum, error) {
+	curriculumBytes, err := json.Marshal(run.InputData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stored curriculum: %w", err)
+	}
+
+	var curriculum models.Curriculum
+	if err := json.Unmarshal(curriculumBytes, &curriculum); err != nil {
+		return nil, fmt.Errorf("failed to parse stored curriculum: %w", err)
+	}
+
+	return &curriculum, nil
+}
@@ -3,6 +3,7 @@ package pipeline
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/kavishankarks/document-hub/go-api/internal/models"
@@ -45,20 +46,116 @@ func (p *CurriculumParser) ParseYAML(data string) (*models.Curriculum, error) {
 	return &curriculum, nil
 }
 
-// ParseMarkdown parses curriculum from markdown-style text
+// frontmatter holds the course-level metadata parsed from the `---` fenced
+// YAML block at the top of a curriculum Markdown document.
+type frontmatter struct {
+	Author     string   `yaml:"author"`
+	Tags       []string `yaml:"tags"`
+	Difficulty string   `yaml:"difficulty"`
+}
+
+var tagAnnotationRe = regexp.MustCompile(`\[tag:([^\]]+)\]`)
+
+// ParseMarkdown parses curriculum from markdown-style text.
+//
 // Expected format:
-// # Course Title
-// ## Module: Module Name
-// - Topic 1
-// - Topic 2
+//
+//	---
+//	author: Jane Doe
+//	tags: [go, backend]
+//	difficulty: intermediate
+//	---
+//	# Course Title
+//	## Module: Module Name
+//	### Topic Name [tag:foo]
+//	#### Prerequisite topic
+//	> objective: Understand the topic
+//	- Flat topic (legacy bullet form, still supported)
 func (p *CurriculumParser) ParseMarkdown(data string) (*models.Curriculum, error) {
+	curriculum, _, err := p.parseMarkdown(data, false)
+	return curriculum, err
+}
+
+// ParseMarkdownStrict parses curriculum Markdown the same way as
+// ParseMarkdown, but instead of stopping at the first problem it collects
+// every line-numbered issue it finds (malformed frontmatter, topics, etc.)
+// and returns them all, mirroring the lint-with-multiple-errors pattern used
+// elsewhere in the pipeline package's validators.
+func (p *CurriculumParser) ParseMarkdownStrict(data string) (*models.Curriculum, []ParseError) {
+	curriculum, parseErrors, _ := p.parseMarkdown(data, true)
+	return curriculum, parseErrors
+}
+
+// ParseError describes a single problem found while strictly parsing a
+// curriculum Markdown document, anchored to the 1-indexed source line.
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// parseMarkdown is the shared implementation behind ParseMarkdown and
+// ParseMarkdownStrict. In strict mode it accumulates ParseErrors instead of
+// returning on the first validation failure.
+func (p *CurriculumParser) parseMarkdown(data string, strict bool) (*models.Curriculum, []ParseError, error) {
 	lines := strings.Split(data, "\n")
 
 	var curriculum models.Curriculum
+	var parseErrors []ParseError
 	var currentModule *models.CurriculumModule
+	var currentTopicMeta *models.CurriculumTopicMeta
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	fail := func(lineNum int, format string, args ...interface{}) error {
+		msg := fmt.Sprintf(format, args...)
+		parseErrors = append(parseErrors, ParseError{Line: lineNum, Message: msg})
+		if strict {
+			return nil
+		}
+		return fmt.Errorf("line %d: %s", lineNum, msg)
+	}
+
+	flushModule := func() {
+		if currentModule != nil {
+			curriculum.Modules = append(curriculum.Modules, *currentModule)
+		}
+	}
+
+	startIdx := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		end := -1
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				end = i
+				break
+			}
+		}
+
+		if end == -1 {
+			if err := fail(1, "unterminated frontmatter fence"); err != nil {
+				return nil, parseErrors, err
+			}
+		} else {
+			var fm frontmatter
+			fmBlock := strings.Join(lines[1:end], "\n")
+			if err := yaml.Unmarshal([]byte(fmBlock), &fm); err != nil {
+				if ferr := fail(1, "invalid frontmatter: %v", err); ferr != nil {
+					return nil, parseErrors, ferr
+				}
+			} else {
+				curriculum.Author = fm.Author
+				curriculum.Tags = fm.Tags
+				curriculum.Difficulty = fm.Difficulty
+			}
+			startIdx = end + 1
+		}
+	}
+
+	for i := startIdx; i < len(lines); i++ {
+		lineNum := i + 1
+		line := strings.TrimSpace(lines[i])
 
 		if line == "" {
 			continue
@@ -72,41 +169,114 @@ func (p *CurriculumParser) ParseMarkdown(data string) (*models.Curriculum, error
 
 		// Module name (## Module: Name or ## Name)
 		if strings.HasPrefix(line, "## ") {
+			flushModule()
+
 			moduleName := strings.TrimPrefix(line, "## ")
 			moduleName = strings.TrimPrefix(moduleName, "Module: ")
 
-			if currentModule != nil {
-				curriculum.Modules = append(curriculum.Modules, *currentModule)
-			}
-
 			currentModule = &models.CurriculumModule{
 				Name:   moduleName,
 				Topics: []string{},
 			}
+			currentTopicMeta = nil
+			continue
+		}
+
+		// Topic heading (### Topic Name [tag:foo])
+		if strings.HasPrefix(line, "### ") {
+			if currentModule == nil {
+				if err := fail(lineNum, "topic heading found before any module"); err != nil {
+					return nil, parseErrors, err
+				}
+				continue
+			}
+
+			topicName, tags := extractTagAnnotations(strings.TrimPrefix(line, "### "))
+
+			currentModule.Topics = append(currentModule.Topics, topicName)
+			currentModule.TopicMeta = append(currentModule.TopicMeta, models.CurriculumTopicMeta{
+				Name: topicName,
+				Tags: tags,
+			})
+			currentTopicMeta = &currentModule.TopicMeta[len(currentModule.TopicMeta)-1]
+			continue
+		}
+
+		// Sub-topic heading (#### Prerequisite breadcrumb)
+		if strings.HasPrefix(line, "#### ") {
+			if currentTopicMeta == nil {
+				if err := fail(lineNum, "sub-topic heading found before any topic"); err != nil {
+					return nil, parseErrors, err
+				}
+				continue
+			}
+
+			subTopic, tags := extractTagAnnotations(strings.TrimPrefix(line, "#### "))
+			currentTopicMeta.Prerequisites = append(currentTopicMeta.Prerequisites, subTopic)
+			currentTopicMeta.Tags = append(currentTopicMeta.Tags, tags...)
 			continue
 		}
 
-		// Topics (- Topic or * Topic)
+		// Learning objective blockquote (> objective: ...)
+		if strings.HasPrefix(line, ">") {
+			rest := strings.TrimSpace(strings.TrimPrefix(line, ">"))
+			if objective, ok := strings.CutPrefix(rest, "objective:"); ok {
+				if currentTopicMeta == nil {
+					if err := fail(lineNum, "objective blockquote found before any topic"); err != nil {
+						return nil, parseErrors, err
+					}
+					continue
+				}
+				currentTopicMeta.LearningObjectives = append(currentTopicMeta.LearningObjectives, strings.TrimSpace(objective))
+			}
+			continue
+		}
+
+		// Flat topics (- Topic or * Topic), kept for backwards compatibility
+		// with the original bullet-list DSL.
 		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
-			if currentModule != nil {
-				topic := strings.TrimPrefix(line, "- ")
-				topic = strings.TrimPrefix(topic, "* ")
-				currentModule.Topics = append(currentModule.Topics, topic)
+			if currentModule == nil {
+				if err := fail(lineNum, "topic bullet found before any module"); err != nil {
+					return nil, parseErrors, err
+				}
+				continue
+			}
+
+			topic := strings.TrimPrefix(line, "- ")
+			topic = strings.TrimPrefix(topic, "* ")
+			topic, tags := extractTagAnnotations(topic)
+
+			currentModule.Topics = append(currentModule.Topics, topic)
+			if len(tags) > 0 {
+				currentModule.TopicMeta = append(currentModule.TopicMeta, models.CurriculumTopicMeta{
+					Name: topic,
+					Tags: tags,
+				})
 			}
+			currentTopicMeta = nil
 			continue
 		}
 	}
 
-	// Add the last module
-	if currentModule != nil {
-		curriculum.Modules = append(curriculum.Modules, *currentModule)
-	}
+	flushModule()
 
-	if err := p.validate(&curriculum); err != nil {
-		return nil, err
+	if err := p.validateLines(&curriculum, fail); err != nil {
+		return nil, parseErrors, err
 	}
 
-	return &curriculum, nil
+	return &curriculum, parseErrors, nil
+}
+
+// extractTagAnnotations strips inline [tag:foo] annotations from a line,
+// returning the cleaned text and the collected tag names.
+func extractTagAnnotations(line string) (string, []string) {
+	var tags []string
+	matches := tagAnnotationRe.FindAllStringSubmatch(line, -1)
+	for _, m := range matches {
+		tags = append(tags, strings.TrimSpace(m[1]))
+	}
+	cleaned := strings.TrimSpace(tagAnnotationRe.ReplaceAllString(line, ""))
+	return cleaned, tags
 }
 
 // ParseAuto automatically detects format and parses
@@ -149,6 +319,39 @@ func (p *CurriculumParser) validate(curriculum *models.Curriculum) error {
 	return nil
 }
 
+// validateLines is validate's strict-mode counterpart: it reports every
+// problem via fail instead of returning on the first one. fail appends a
+// ParseError and, in non-strict mode, also returns a non-nil error so the
+// caller can bail out immediately.
+func (p *CurriculumParser) validateLines(curriculum *models.Curriculum, fail func(line int, format string, args ...interface{}) error) error {
+	if curriculum.Title == "" {
+		if err := fail(1, "curriculum title is required"); err != nil {
+			return err
+		}
+	}
+
+	if len(curriculum.Modules) == 0 {
+		if err := fail(1, "curriculum must have at least one module"); err != nil {
+			return err
+		}
+	}
+
+	for i, module := range curriculum.Modules {
+		if module.Name == "" {
+			if err := fail(1, "module %d: name is required", i); err != nil {
+				return err
+			}
+		}
+		if len(module.Topics) == 0 {
+			if err := fail(1, "module %s: must have at least one topic", module.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // ExtractAllTopics extracts all topics from curriculum
 func (p *CurriculumParser) ExtractAllTopics(curriculum *models.Curriculum) []string {
 	var topics []string
@@ -158,6 +361,20 @@ func (p *CurriculumParser) ExtractAllTopics(curriculum *models.Curriculum) []str
 	return topics
 }
 
+// FindTopicMeta looks up the richer per-topic metadata (learning objectives,
+// prerequisites, tags) parsed from the Markdown DSL for a given topic name,
+// if any was recorded.
+func (p *CurriculumParser) FindTopicMeta(curriculum *models.Curriculum, topic string) *models.CurriculumTopicMeta {
+	for _, module := range curriculum.Modules {
+		for i := range module.TopicMeta {
+			if module.TopicMeta[i].Name == topic {
+				return &module.TopicMeta[i]
+			}
+		}
+	}
+	return nil
+}
+
 // GenerateTopicContext generates context string for a topic
 func (p *CurriculumParser) GenerateTopicContext(
 	curriculum *models.Curriculum,
@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+)
+
+// throughputWindow bounds how far back ProgressTracker looks when computing
+// a stage's rolling units/sec, so a burst of completions early in a
+// long-running stage doesn't make a later slowdown look falsely fast.
+const throughputWindow = 30 * time.Second
+
+// stageProgressState is one stage's live progress within a single run: how
+// many of its total units have completed, plus the timestamp of every
+// completion within throughputWindow (oldest first), used to derive a
+// rolling throughput and ETA.
+type stageProgressState struct {
+	total       int
+	completed   int
+	completions []time.Time
+}
+
+// ProgressTracker tracks per-stage completed/total unit counts for one
+// pipeline run and derives a rolling throughput/ETA from them. It
+// complements processPipeline's hard-coded progress percentages
+// (5/15/40/55/85/100), which remain the coarse overall Progress and don't
+// reflect actual work done, with numbers tied to each stage's real unit
+// count - topics to search/normalize, topics to chunk+embed.
+type ProgressTracker struct {
+	mu     sync.Mutex
+	stages map[string]*stageProgressState
+}
+
+// NewProgressTracker creates an empty tracker; stages are registered lazily
+// by SetStageTotal/RecordUnit as processPipeline reaches them.
+func NewProgressTracker() *ProgressTracker {
+	return &ProgressTracker{stages: make(map[string]*stageProgressState)}
+}
+
+func (t *ProgressTracker) stateFor(stage string) *stageProgressState {
+	s, ok := t.stages[stage]
+	if !ok {
+		s = &stageProgressState{}
+		t.stages[stage] = s
+	}
+	return s
+}
+
+// SetStageTotal records stage's total unit count so RecordUnit's
+// completed/total ratio and ETA are meaningful from the stage's very first
+// completed unit, rather than climbing from an unknown total.
+func (t *ProgressTracker) SetStageTotal(stage string, total int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stateFor(stage).total = total
+}
+
+// RecordUnit marks one more unit of stage complete and returns a snapshot of
+// its current models.StageProgress, for the caller to publish as an event.
+func (t *ProgressTracker) RecordUnit(stage string) models.StageProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.stateFor(stage)
+	s.completed++
+
+	now := time.Now()
+	s.completions = append(s.completions, now)
+	cutoff := now.Add(-throughputWindow)
+	i := 0
+	for i < len(s.completions) && s.completions[i].Before(cutoff) {
+		i++
+	}
+	s.completions = s.completions[i:]
+
+	return t.snapshotLocked(stage)
+}
+
+// Snapshot returns every stage's current models.StageProgress, for
+// GetPipelineStatus.
+func (t *ProgressTracker) Snapshot() map[string]models.StageProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(map[string]models.StageProgress, len(t.stages))
+	for stage := range t.stages {
+		out[stage] = t.snapshotLocked(stage)
+	}
+	return out
+}
+
+func (t *ProgressTracker) snapshotLocked(stage string) models.StageProgress {
+	s := t.stateFor(stage)
+
+	progress := models.StageProgress{Completed: s.completed, Total: s.total}
+
+	if window := windowSpan(s.completions); window > 0 {
+		progress.Throughput = float64(len(s.completions)-1) / window.Seconds()
+	}
+
+	if progress.Throughput > 0 && s.total > s.completed {
+		eta := float64(s.total-s.completed) / progress.Throughput
+		progress.ETASeconds = &eta
+	}
+
+	return progress
+}
+
+// windowSpan is the time between the oldest and most recent recorded
+// completion, the divisor for the rolling units/sec estimate. Fewer than two
+// samples have no span to divide by, so the caller leaves Throughput at its
+// zero value instead of reporting an instantaneous (and misleading) rate off
+// a single completion.
+func windowSpan(completions []time.Time) time.Duration {
+	if len(completions) < 2 {
+		return 0
+	}
+	return completions[len(completions)-1].Sub(completions[0])
+}
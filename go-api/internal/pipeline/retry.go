@@ -0,0 +1,97 @@
+package pipeline
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+)
+
+// RetryPolicy controls withRetry's exponential-backoff-with-jitter loop. The
+// zero value isn't useful on its own - use retryPolicyFromConfig or
+// defaultRetryPolicy.
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// defaultRetryPolicy is used when a run's PipelineConfig doesn't override
+// these knobs (see StartPipeline's other default-filling).
+var defaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// retryPolicyFromConfig builds a RetryPolicy from a run's PipelineConfig,
+// falling back to defaultRetryPolicy field-by-field so a run that only sets
+// MaxRetries still gets sane backoff bounds.
+func retryPolicyFromConfig(config models.PipelineConfig) RetryPolicy {
+	policy := defaultRetryPolicy
+	if config.MaxRetries > 0 {
+		policy.MaxRetries = config.MaxRetries
+	}
+	if config.InitialBackoffMs > 0 {
+		policy.InitialBackoff = time.Duration(config.InitialBackoffMs) * time.Millisecond
+	}
+	if config.MaxBackoffMs > 0 {
+		policy.MaxBackoff = time.Duration(config.MaxBackoffMs) * time.Millisecond
+	}
+	return policy
+}
+
+// withRetry calls fn until it succeeds, ctx is done, it returns a
+// non-retryable error, or policy.MaxRetries retries are exhausted -
+// whichever comes first. A terminal error (anything that isn't an
+// *errs.Error with Retryable set) is returned immediately without
+// consuming a retry, matching the request's "classify errors as retryable
+// vs terminal" rather than treating every failure the same way
+// enrichTopicsWithSearch/chunkAndEmbedTopics used to.
+//
+// The wait before each retry is capped exponential backoff
+// (InitialBackoff * 2^attempt, clamped to MaxBackoff) plus up to 20% jitter
+// so a burst of topics retrying in lockstep doesn't all hammer the
+// embedding service on the same tick; an *errs.Error's RetryAfter, when
+// set (e.g. from a 429's Retry-After header), overrides that computed wait
+// instead of being added to it.
+func withRetry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	var lastErr error
+
+	for attempt := 0; ; attempt++ {
+		lastErr = fn()
+		if lastErr == nil {
+			return nil
+		}
+
+		e, ok := errs.As(lastErr)
+		if !ok || !e.Retryable {
+			return lastErr
+		}
+		if attempt >= policy.MaxRetries {
+			return lastErr
+		}
+
+		wait := backoffWithJitter(policy, attempt)
+		if e.RetryAfter > 0 {
+			wait = e.RetryAfter
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func backoffWithJitter(policy RetryPolicy, attempt int) time.Duration {
+	backoff := policy.InitialBackoff * time.Duration(1<<uint(attempt))
+	if backoff > policy.MaxBackoff || backoff <= 0 {
+		backoff = policy.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff)/5 + 1))
+	return backoff + jitter
+}
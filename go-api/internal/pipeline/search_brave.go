@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// BraveProvider implements SearchProvider against the Brave Search API.
+type BraveProvider struct {
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewBraveProvider builds a BraveProvider, reading its API key from
+// BRAVE_API_KEY.
+func NewBraveProvider() *BraveProvider {
+	return &BraveProvider{
+		apiKey:     os.Getenv("BRAVE_API_KEY"),
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type braveSearchResponse struct {
+	Web struct {
+		Results []struct {
+			URL         string `json:"url"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			Age         string `json:"age"`
+		} `json:"results"`
+	} `json:"web"`
+}
+
+func (b *BraveProvider) Search(ctx context.Context, query string, n int) ([]SearchHit, error) {
+	if b.apiKey == "" {
+		return nil, fmt.Errorf("BRAVE_API_KEY is not configured")
+	}
+
+	reqURL := fmt.Sprintf("https://api.search.brave.com/res/v1/web/search?q=%s&count=%d", url.QueryEscape(query), n)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build brave request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", b.apiKey)
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call brave search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("brave search returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var parsed braveSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode brave response: %w", err)
+	}
+
+	hits := make([]SearchHit, 0, len(parsed.Web.Results))
+	for _, r := range parsed.Web.Results {
+		if len(hits) >= n {
+			break
+		}
+		hits = append(hits, SearchHit{URL: r.URL, Title: r.Title, Snippet: r.Description})
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,76 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DuckDuckGoProvider implements SearchProvider by scraping DuckDuckGo's
+// HTML-only results page, which needs no API key.
+type DuckDuckGoProvider struct {
+	httpClient *http.Client
+}
+
+// NewDuckDuckGoProvider builds a DuckDuckGoProvider.
+func NewDuckDuckGoProvider() *DuckDuckGoProvider {
+	return &DuckDuckGoProvider{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// ddgResultPattern pulls the result link, title, and snippet out of
+// html.duckduckgo.com's results markup.
+var ddgResultPattern = regexp.MustCompile(`(?s)<a rel="nofollow" class="result__a"[^>]*href="(.*?)"[^>]*>(.*?)</a>.*?class="result__snippet"[^>]*>(.*?)</a>`)
+
+// ddgTagPattern strips the inline tags (e.g. <b>) DuckDuckGo uses to
+// highlight query terms within titles and snippets.
+var ddgTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func (d *DuckDuckGoProvider) Search(ctx context.Context, query string, n int) ([]SearchHit, error) {
+	reqURL := "https://html.duckduckgo.com/html/?q=" + url.QueryEscape(query)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build duckduckgo request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; itp-rag-processor/1.0)")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call duckduckgo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("duckduckgo returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read duckduckgo response: %w", err)
+	}
+
+	var hits []SearchHit
+	for _, m := range ddgResultPattern.FindAllStringSubmatch(string(body), -1) {
+		if len(hits) >= n {
+			break
+		}
+		hits = append(hits, SearchHit{
+			URL:     ddgCleanText(m[1]),
+			Title:   ddgCleanText(m[2]),
+			Snippet: ddgCleanText(m[3]),
+		})
+	}
+
+	return hits, nil
+}
+
+func ddgCleanText(s string) string {
+	return strings.TrimSpace(ddgTagPattern.ReplaceAllString(s, ""))
+}
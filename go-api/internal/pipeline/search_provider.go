@@ -0,0 +1,257 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SearchHit is a single organic result returned by a SearchProvider.
+type SearchHit struct {
+	URL         string
+	Title       string
+	Snippet     string
+	PublishedAt time.Time
+}
+
+// SearchProvider abstracts a web-search backend so the enrichment stage
+// (enrichTopicsWithSearch / processSearchJob) can swap engines via the
+// SEARCH_ENGINE environment variable (see NewSearchProviderFromEnv) instead
+// of being hard-wired to one. PipelineConfig.SearchEngine is not read.
+type SearchProvider interface {
+	Search(ctx context.Context, query string, n int) ([]SearchHit, error)
+}
+
+// searchProviderFactories maps a SEARCH_ENGINE value to its SearchProvider
+// constructor.
+var searchProviderFactories = map[string]func() SearchProvider{
+	"duckduckgo": func() SearchProvider { return NewDuckDuckGoProvider() },
+	"brave":      func() SearchProvider { return NewBraveProvider() },
+	"searxng":    func() SearchProvider { return NewSearXNGProvider() },
+	"tavily":     func() SearchProvider { return NewTavilyProvider() },
+}
+
+// NewSearchProvider builds the named provider wrapped with a rate-limit and
+// retry-with-backoff decorator, so a single slow or rate-limiting engine
+// degrades gracefully instead of failing the enrichment stage outright.
+func NewSearchProvider(name string) (SearchProvider, error) {
+	factory, ok := searchProviderFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown search provider %q", name)
+	}
+	return newRateLimitedProvider(factory()), nil
+}
+
+// NewSearchProviderFromEnv builds a SearchProvider from the SEARCH_ENGINE
+// environment variable, which may name a single engine (duckduckgo, brave,
+// searxng, tavily) or a comma-separated list. A list is combined with a
+// CompositeProvider so one engine rate-limiting the run doesn't stall
+// enrichment. Returns a nil provider (and nil error) when SEARCH_ENGINE is
+// unset, so callers fall back to their existing behavior.
+func NewSearchProviderFromEnv() (SearchProvider, error) {
+	raw := strings.TrimSpace(os.Getenv("SEARCH_ENGINE"))
+	if raw == "" {
+		return nil, nil
+	}
+
+	var providers []SearchProvider
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		provider, err := NewSearchProvider(name)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, nil
+	case 1:
+		return providers[0], nil
+	default:
+		return NewCompositeProvider(providers...), nil
+	}
+}
+
+// rateLimitedProvider decorates a SearchProvider with a minimum interval
+// between calls and exponential-backoff retries, so callers don't need to
+// reimplement rate-limit handling per engine.
+type rateLimitedProvider struct {
+	inner       SearchProvider
+	minInterval time.Duration
+	maxRetries  int
+
+	mu       sync.Mutex
+	lastCall time.Time
+}
+
+func newRateLimitedProvider(inner SearchProvider) *rateLimitedProvider {
+	return &rateLimitedProvider{
+		inner:       inner,
+		minInterval: time.Second,
+		maxRetries:  3,
+	}
+}
+
+func (r *rateLimitedProvider) Search(ctx context.Context, query string, n int) ([]SearchHit, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	backoff := 500 * time.Millisecond
+	for attempt := 0; attempt <= r.maxRetries; attempt++ {
+		hits, err := r.inner.Search(ctx, query, n)
+		if err == nil {
+			return hits, nil
+		}
+		lastErr = err
+
+		if attempt == r.maxRetries {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+
+	return nil, fmt.Errorf("search provider failed after %d attempts: %w", r.maxRetries+1, lastErr)
+}
+
+// wait blocks until minInterval has elapsed since the last call, enforcing
+// a simple per-provider rate limit.
+func (r *rateLimitedProvider) wait(ctx context.Context) error {
+	r.mu.Lock()
+	elapsed := time.Since(r.lastCall)
+	wait := r.minInterval - elapsed
+	r.lastCall = time.Now()
+	r.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CompositeProvider fans a query out to multiple SearchProvider backends
+// concurrently and merges their hits, deduplicated by canonicalized URL.
+// This makes the enrichment stage resilient to a single engine
+// rate-limiting or failing the run: the other engines' hits still come
+// back.
+type CompositeProvider struct {
+	providers []SearchProvider
+}
+
+// NewCompositeProvider fans a query out to all of the given providers.
+func NewCompositeProvider(providers ...SearchProvider) *CompositeProvider {
+	return &CompositeProvider{providers: providers}
+}
+
+func (c *CompositeProvider) Search(ctx context.Context, query string, n int) ([]SearchHit, error) {
+	type outcome struct {
+		hits []SearchHit
+		err  error
+	}
+
+	outcomes := make([]outcome, len(c.providers))
+	var wg sync.WaitGroup
+	for i, provider := range c.providers {
+		wg.Add(1)
+		go func(i int, provider SearchProvider) {
+			defer wg.Done()
+			hits, err := provider.Search(ctx, query, n)
+			outcomes[i] = outcome{hits: hits, err: err}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	var merged []SearchHit
+	var errs []error
+	for _, o := range outcomes {
+		if o.err != nil {
+			errs = append(errs, o.err)
+			continue
+		}
+		for _, hit := range o.hits {
+			key := canonicalizeURL(hit.URL)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, hit)
+		}
+	}
+
+	if len(merged) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("all search providers failed: %v", errs)
+	}
+
+	if len(merged) > n {
+		merged = merged[:n]
+	}
+
+	return merged, nil
+}
+
+// canonicalizeURL normalizes a URL for deduplication: lowercases the host,
+// strips a leading "www.", and drops the scheme, query string, fragment,
+// and trailing slash, so http://www.Example.com/x?utm=1 and
+// https://example.com/x/ are recognized as the same hit.
+func canonicalizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return strings.ToLower(strings.TrimSuffix(raw, "/"))
+	}
+
+	host := strings.ToLower(strings.TrimPrefix(u.Host, "www."))
+	path := strings.TrimSuffix(u.Path, "/")
+
+	return host + path
+}
+
+// buildEnrichment turns raw search hits into the combined_content and
+// results shape the enrichment stage already expects (mirroring what the
+// embedding service's /enrich_topic endpoint returns), so enrichTopicsWithSearch
+// and processSearchJob don't care whether hits came from a SearchProvider or
+// from embeddingClient.EnrichTopic.
+func buildEnrichment(hits []SearchHit) (string, []interface{}) {
+	var content strings.Builder
+	results := make([]interface{}, 0, len(hits))
+
+	for _, hit := range hits {
+		content.WriteString(hit.Title)
+		content.WriteString("\n")
+		content.WriteString(hit.Snippet)
+		content.WriteString("\n\n")
+
+		result := map[string]interface{}{
+			"url":     hit.URL,
+			"title":   hit.Title,
+			"snippet": hit.Snippet,
+		}
+		if !hit.PublishedAt.IsZero() {
+			result["published_at"] = hit.PublishedAt
+		}
+		results = append(results, result)
+	}
+
+	return content.String(), results
+}
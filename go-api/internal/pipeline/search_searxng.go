@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// SearXNGProvider implements SearchProvider against a self-hosted SearXNG
+// instance's JSON results endpoint.
+type SearXNGProvider struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewSearXNGProvider builds a SearXNGProvider pointed at SEARXNG_URL,
+// defaulting to a local instance.
+func NewSearXNGProvider() *SearXNGProvider {
+	baseURL := os.Getenv("SEARXNG_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	return &SearXNGProvider{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type searxngResponse struct {
+	Results []struct {
+		URL           string `json:"url"`
+		Title         string `json:"title"`
+		Content       string `json:"content"`
+		PublishedDate string `json:"publishedDate"`
+	} `json:"results"`
+}
+
+func (s *SearXNGProvider) Search(ctx context.Context, query string, n int) ([]SearchHit, error) {
+	reqURL := fmt.Sprintf("%s/search?q=%s&format=json", s.baseURL, url.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build searxng request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call searxng: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("searxng returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var parsed searxngResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode searxng response: %w", err)
+	}
+
+	hits := make([]SearchHit, 0, len(parsed.Results))
+	for _, r := range parsed.Results {
+		if len(hits) >= n {
+			break
+		}
+
+		hit := SearchHit{URL: r.URL, Title: r.Title, Snippet: r.Content}
+		if t, err := time.Parse(time.RFC3339, r.PublishedDate); err == nil {
+			hit.PublishedAt = t
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TavilyProvider implements SearchProvider against the Tavily Search API.
+type TavilyProvider struct {
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewTavilyProvider builds a TavilyProvider, reading its API key from
+// TAVILY_API_KEY.
+func NewTavilyProvider() *TavilyProvider {
+	return &TavilyProvider{
+		apiKey:     os.Getenv("TAVILY_API_KEY"),
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+type tavilySearchRequest struct {
+	APIKey     string `json:"api_key"`
+	Query      string `json:"query"`
+	MaxResults int    `json:"max_results"`
+}
+
+type tavilySearchResponse struct {
+	Results []struct {
+		URL     string `json:"url"`
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+func (t *TavilyProvider) Search(ctx context.Context, query string, n int) ([]SearchHit, error) {
+	if t.apiKey == "" {
+		return nil, fmt.Errorf("TAVILY_API_KEY is not configured")
+	}
+
+	reqBody := tavilySearchRequest{APIKey: t.apiKey, Query: query, MaxResults: n}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tavily request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.tavily.com/search", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tavily request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call tavily: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("tavily returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var parsed tavilySearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode tavily response: %w", err)
+	}
+
+	hits := make([]SearchHit, 0, len(parsed.Results))
+	for _, r := range parsed.Results {
+		if len(hits) >= n {
+			break
+		}
+		hits = append(hits, SearchHit{URL: r.URL, Title: r.Title, Snippet: r.Content})
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+)
+
+// StateStore persists pipeline run and topic state outside any single API
+// process's memory, so a run's history survives a restart and more than one
+// API replica can observe (and make progress on) the same run instead of
+// requiring sticky routing to whichever process happened to start it. This
+// is the abstraction behind Orchestrator.SetStateStore; see
+// EtcdStateStore and PostgresStateStore for the two implementations.
+//
+// CompareAndSwapRun is the only mutating run method: every stage-progress
+// write goes through it (see Orchestrator.updatePipelineStatus) so two
+// workers racing to update the same run - e.g. a slow stage's completion
+// landing after a later stage already started - can't silently clobber each
+// other's write. PutRun is for the one unconditional write, creating a
+// brand new run.
+type StateStore interface {
+	// GetRun loads a single run, or a NotFound *errs.Error if it doesn't
+	// exist.
+	GetRun(ctx context.Context, id uint) (*models.PipelineRun, error)
+
+	// PutRun unconditionally creates or overwrites a run. Only StartPipeline
+	// should call this directly; every later write should go through
+	// CompareAndSwapRun.
+	PutRun(ctx context.Context, run *models.PipelineRun) error
+
+	// ListRuns returns up to limit runs starting at offset, sorted by
+	// CreatedAt descending (newest first), plus the total run count.
+	ListRuns(ctx context.Context, limit, offset int) ([]models.PipelineRun, int64, error)
+
+	// GetTopics loads every topic belonging to runID.
+	GetTopics(ctx context.Context, runID uint) ([]models.CurriculumTopic, error)
+
+	// UpdateTopic unconditionally overwrites a topic. Topic writes happen
+	// inside a single stage's worker and aren't contended the way run
+	// writes are (each topic node only has one goroutine working it at a
+	// time - see runGraphStage), so topics don't need CAS.
+	UpdateTopic(ctx context.Context, topic *models.CurriculumTopic) error
+
+	// CompareAndSwapRun loads the current run, applies mutate to it, and
+	// writes the result back only if nothing else modified the run since
+	// the load. On a conflicting write it reloads the now-current run,
+	// re-applies mutate, and retries - mirroring the retry loop etcd3's own
+	// STM/GuaranteedUpdate helpers use around a transaction compared on
+	// mod_revision. mutate should be side-effect-free beyond the run it's
+	// given, since it may run more than once.
+	CompareAndSwapRun(ctx context.Context, id uint, mutate func(*models.PipelineRun) error) error
+
+	// PutLogArtifact persists runID's flushed log lines (see LineWriter) as
+	// a JSON-encoded logs.json blob. Called when a run reaches a terminal
+	// status, and again after RetryFailedTopics re-runs a run's topics; each
+	// call replaces the previous artifact.
+	PutLogArtifact(ctx context.Context, runID uint, data []byte) error
+
+	// GetLogArtifact returns runID's previously flushed log artifact, or a
+	// nil slice (with a nil error) if none was ever flushed - e.g. the run
+	// is still in progress, or predates this feature.
+	GetLogArtifact(ctx context.Context, runID uint) ([]byte, error)
+}
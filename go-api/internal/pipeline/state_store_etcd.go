@@ -0,0 +1,220 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+)
+
+// EtcdStateStore is a StateStore backed by etcd3, for deployments running
+// more than one API replica without a shared Postgres: any replica can
+// read/write a run's state through etcd instead of requiring requests for a
+// given run to be routed back to whichever process started it. See
+// PostgresStateStore for the single-database alternative.
+//
+// Runs are stored one key per run at <prefix>/runs/<id>, topics at
+// <prefix>/topics/<runID>/<topicID>, each value a JSON-encoded struct.
+// CompareAndSwapRun uses etcd's per-key mod_revision as the optimistic
+// concurrency token (etcd bumps it on every write to that key), so there's
+// no separate version counter to maintain the way PostgresStateStore needs
+// one.
+type EtcdStateStore struct {
+	client *clientv3.Client
+	prefix string
+}
+
+// NewEtcdStateStore wraps an already-connected *clientv3.Client. prefix
+// namespaces every key this store touches (e.g. "/itp-rag-processor"), so
+// multiple environments can share one etcd cluster.
+func NewEtcdStateStore(client *clientv3.Client, prefix string) *EtcdStateStore {
+	return &EtcdStateStore{client: client, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+func (s *EtcdStateStore) runKey(id uint) string {
+	return fmt.Sprintf("%s/runs/%d", s.prefix, id)
+}
+
+func (s *EtcdStateStore) runsPrefix() string {
+	return s.prefix + "/runs/"
+}
+
+func (s *EtcdStateStore) topicKey(runID, topicID uint) string {
+	return fmt.Sprintf("%s/topics/%d/%d", s.prefix, runID, topicID)
+}
+
+func (s *EtcdStateStore) topicsPrefix(runID uint) string {
+	return fmt.Sprintf("%s/topics/%d/", s.prefix, runID)
+}
+
+func (s *EtcdStateStore) logArtifactKey(runID uint) string {
+	return fmt.Sprintf("%s/logs/%d", s.prefix, runID)
+}
+
+func (s *EtcdStateStore) GetRun(ctx context.Context, id uint) (*models.PipelineRun, error) {
+	resp, err := s.client.Get(ctx, s.runKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pipeline run %d from etcd: %w", id, err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, errs.Newf(errs.CodeNotFound, "pipeline run %d not found", id)
+	}
+
+	var run models.PipelineRun
+	if err := json.Unmarshal(resp.Kvs[0].Value, &run); err != nil {
+		return nil, fmt.Errorf("failed to decode pipeline run %d: %w", id, err)
+	}
+	return &run, nil
+}
+
+func (s *EtcdStateStore) PutRun(ctx context.Context, run *models.PipelineRun) error {
+	value, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode pipeline run %d: %w", run.ID, err)
+	}
+	if _, err := s.client.Put(ctx, s.runKey(run.ID), string(value)); err != nil {
+		return fmt.Errorf("failed to put pipeline run %d: %w", run.ID, err)
+	}
+	return nil
+}
+
+// ListRuns range-scans every run key under runsPrefix, decodes them, sorts
+// by CreatedAt descending, and slices out [offset, offset+limit). etcd's
+// range queries sort by key (i.e. by ID) rather than by an arbitrary field,
+// so unlike ClaimNext-style keyset pagination this fetches the full set and
+// sorts/pages in memory - acceptable here since a deployment's run count is
+// expected to stay in the thousands, not the millions.
+func (s *EtcdStateStore) ListRuns(ctx context.Context, limit, offset int) ([]models.PipelineRun, int64, error) {
+	resp, err := s.client.Get(ctx, s.runsPrefix(), clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list pipeline runs from etcd: %w", err)
+	}
+
+	runs := make([]models.PipelineRun, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var run models.PipelineRun
+		if err := json.Unmarshal(kv.Value, &run); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode pipeline run at %s: %w", kv.Key, err)
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].CreatedAt.After(runs[j].CreatedAt) })
+
+	total := int64(len(runs))
+	if offset >= len(runs) {
+		return []models.PipelineRun{}, total, nil
+	}
+	end := offset + limit
+	if end > len(runs) {
+		end = len(runs)
+	}
+	return runs[offset:end], total, nil
+}
+
+func (s *EtcdStateStore) GetTopics(ctx context.Context, runID uint) ([]models.CurriculumTopic, error) {
+	resp, err := s.client.Get(ctx, s.topicsPrefix(runID), clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics for pipeline run %d: %w", runID, err)
+	}
+
+	topics := make([]models.CurriculumTopic, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var topic models.CurriculumTopic
+		if err := json.Unmarshal(kv.Value, &topic); err != nil {
+			return nil, fmt.Errorf("failed to decode topic at %s: %w", kv.Key, err)
+		}
+		topics = append(topics, topic)
+	}
+	return topics, nil
+}
+
+func (s *EtcdStateStore) UpdateTopic(ctx context.Context, topic *models.CurriculumTopic) error {
+	value, err := json.Marshal(topic)
+	if err != nil {
+		return fmt.Errorf("failed to encode topic %d: %w", topic.ID, err)
+	}
+	if _, err := s.client.Put(ctx, s.topicKey(topic.PipelineRunID, topic.ID), string(value)); err != nil {
+		return fmt.Errorf("failed to put topic %d: %w", topic.ID, err)
+	}
+	return nil
+}
+
+// CompareAndSwapRun mirrors the retry loop etcd3's own STM/GuaranteedUpdate
+// helpers build around a single transaction: load the current value and its
+// mod_revision, apply mutate, then commit a Put guarded by
+// `mod_revision(key) = <the one just read>`. If another writer's Put landed
+// first the compare fails, the transaction reports !Succeeded instead of
+// erroring, and this reloads the now-current run and retries - so it
+// converges instead of silently losing an update the way two unconditional
+// Puts racing would.
+func (s *EtcdStateStore) CompareAndSwapRun(ctx context.Context, id uint, mutate func(*models.PipelineRun) error) error {
+	key := s.runKey(id)
+
+	for attempt := 0; attempt < maxCASRetries; attempt++ {
+		getResp, err := s.client.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to get pipeline run %d from etcd: %w", id, err)
+		}
+		if len(getResp.Kvs) == 0 {
+			return errs.Newf(errs.CodeNotFound, "pipeline run %d not found", id)
+		}
+
+		kv := getResp.Kvs[0]
+		var run models.PipelineRun
+		if err := json.Unmarshal(kv.Value, &run); err != nil {
+			return fmt.Errorf("failed to decode pipeline run %d: %w", id, err)
+		}
+
+		if err := mutate(&run); err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(&run)
+		if err != nil {
+			return fmt.Errorf("failed to encode pipeline run %d: %w", id, err)
+		}
+
+		txnResp, err := s.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", kv.ModRevision)).
+			Then(clientv3.OpPut(key, string(value))).
+			Commit()
+		if err != nil {
+			return fmt.Errorf("failed to commit pipeline run %d update: %w", id, err)
+		}
+		if txnResp.Succeeded {
+			return nil
+		}
+		// mod_revision moved under us; reload and retry against whatever's
+		// there now instead of overwriting a concurrent writer's update.
+	}
+
+	return fmt.Errorf("pipeline run %d: too much write contention, gave up after %d attempts (key=%s)", id, maxCASRetries, key)
+}
+
+// PutLogArtifact stores runID's flushed log lines as a raw JSON blob, unlike
+// runs/topics which round-trip through json.Marshal/Unmarshal here - the
+// caller (Orchestrator.flushLogs) already hands this pre-encoded JSON.
+func (s *EtcdStateStore) PutLogArtifact(ctx context.Context, runID uint, data []byte) error {
+	if _, err := s.client.Put(ctx, s.logArtifactKey(runID), string(data)); err != nil {
+		return fmt.Errorf("failed to put log artifact for pipeline run %d: %w", runID, err)
+	}
+	return nil
+}
+
+func (s *EtcdStateStore) GetLogArtifact(ctx context.Context, runID uint) ([]byte, error) {
+	resp, err := s.client.Get(ctx, s.logArtifactKey(runID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log artifact for pipeline run %d: %w", runID, err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	return resp.Kvs[0].Value, nil
+}
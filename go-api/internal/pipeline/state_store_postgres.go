@@ -0,0 +1,149 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxCASRetries bounds CompareAndSwapRun's reload-and-retry loop so a run
+// under sustained write contention fails loudly instead of spinning
+// forever. In practice a handful of workers racing on one run's handful of
+// stage transitions should never come close to this.
+const maxCASRetries = 20
+
+// PostgresStateStore is a StateStore backed by the same Postgres database
+// used elsewhere (see database.Initialize). It's the default durable
+// backend for users who don't want to also run etcd; see EtcdStateStore for
+// the alternative the request asked for when multiple API replicas need to
+// coordinate without a shared Postgres.
+//
+// It reuses the existing pipeline_runs/curriculum_topics tables (the same
+// ones the job-queue path in orchestrator_jobs.go writes through o.db), so
+// CompareAndSwapRun's optimistic concurrency uses a dedicated Version
+// column (see models.PipelineRun.Version) rather than introducing a second
+// table.
+type PostgresStateStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStateStore wraps an already-initialized *gorm.DB.
+func NewPostgresStateStore(db *gorm.DB) *PostgresStateStore {
+	return &PostgresStateStore{db: db}
+}
+
+func (s *PostgresStateStore) GetRun(ctx context.Context, id uint) (*models.PipelineRun, error) {
+	var run models.PipelineRun
+	if err := s.db.WithContext(ctx).First(&run, id).Error; err != nil {
+		return nil, errs.Wrapf(errs.CodeNotFound, err, "pipeline run %d not found", id)
+	}
+	return &run, nil
+}
+
+func (s *PostgresStateStore) PutRun(ctx context.Context, run *models.PipelineRun) error {
+	if err := s.db.WithContext(ctx).Save(run).Error; err != nil {
+		return fmt.Errorf("failed to persist pipeline run: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStateStore) ListRuns(ctx context.Context, limit, offset int) ([]models.PipelineRun, int64, error) {
+	var runs []models.PipelineRun
+	var total int64
+
+	if err := s.db.WithContext(ctx).Model(&models.PipelineRun{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count pipeline runs: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list pipeline runs: %w", err)
+	}
+
+	return runs, total, nil
+}
+
+func (s *PostgresStateStore) GetTopics(ctx context.Context, runID uint) ([]models.CurriculumTopic, error) {
+	var topics []models.CurriculumTopic
+	if err := s.db.WithContext(ctx).Where("pipeline_run_id = ?", runID).Order("id ASC").Find(&topics).Error; err != nil {
+		return nil, fmt.Errorf("failed to load topics for pipeline run %d: %w", runID, err)
+	}
+	return topics, nil
+}
+
+func (s *PostgresStateStore) UpdateTopic(ctx context.Context, topic *models.CurriculumTopic) error {
+	if err := s.db.WithContext(ctx).Save(topic).Error; err != nil {
+		return fmt.Errorf("failed to persist topic %d: %w", topic.ID, err)
+	}
+	return nil
+}
+
+// CompareAndSwapRun updates run id by reloading it, applying mutate, and
+// writing it back with `WHERE version = <the version it was loaded at>`;
+// zero rows affected means another writer got there first, so it reloads
+// the now-current row and retries. This is the same shape as
+// EtcdStateStore.CompareAndSwapRun's mod_revision compare-and-swap, just
+// using a counter column instead of etcd's built-in revision.
+func (s *PostgresStateStore) CompareAndSwapRun(ctx context.Context, id uint, mutate func(*models.PipelineRun) error) error {
+	for attempt := 0; attempt < maxCASRetries; attempt++ {
+		var run models.PipelineRun
+		if err := s.db.WithContext(ctx).First(&run, id).Error; err != nil {
+			return errs.Wrapf(errs.CodeNotFound, err, "pipeline run %d not found", id)
+		}
+
+		loadedVersion := run.Version
+		if err := mutate(&run); err != nil {
+			return err
+		}
+		run.Version = loadedVersion + 1
+
+		result := s.db.WithContext(ctx).Model(&models.PipelineRun{}).
+			Where("id = ? AND version = ?", id, loadedVersion).
+			Updates(map[string]interface{}{
+				"status":        run.Status,
+				"current_stage": run.CurrentStage,
+				"progress":      run.Progress,
+				"error_message": run.ErrorMessage,
+				"updated_at":    run.UpdatedAt,
+				"version":       run.Version,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update pipeline run %d: %w", id, result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+		// Lost the race to a concurrent writer; reload and retry.
+	}
+
+	return fmt.Errorf("pipeline run %d: too much write contention, gave up after %d attempts", id, maxCASRetries)
+}
+
+// PutLogArtifact upserts runID's flushed log lines, so re-flushing (e.g. a
+// run that's resumed and completes again) replaces rather than duplicates.
+func (s *PostgresStateStore) PutLogArtifact(ctx context.Context, runID uint, data []byte) error {
+	artifact := &models.PipelineLogArtifact{PipelineRunID: runID, Data: data}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "pipeline_run_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "created_at"}),
+	}).Create(artifact).Error
+	if err != nil {
+		return fmt.Errorf("failed to persist log artifact for pipeline run %d: %w", runID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStateStore) GetLogArtifact(ctx context.Context, runID uint) ([]byte, error) {
+	var artifact models.PipelineLogArtifact
+	err := s.db.WithContext(ctx).First(&artifact, "pipeline_run_id = ?", runID).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load log artifact for pipeline run %d: %w", runID, err)
+	}
+	return artifact.Data, nil
+}
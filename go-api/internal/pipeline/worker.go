@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+)
+
+// pollInterval is how often an idle worker checks for ready jobs.
+const pollInterval = 500 * time.Millisecond
+
+// WorkerPool runs a configurable number of goroutines that claim jobs from a
+// JobQueue and execute them through an Orchestrator, so topics are processed
+// with bounded concurrency independent of how many the pipeline enqueues.
+type WorkerPool struct {
+	orchestrator *Orchestrator
+	jobQueue     *JobQueue
+	concurrency  int
+}
+
+// NewWorkerPool creates a worker pool with the given concurrency (number of
+// jobs processed in parallel).
+func NewWorkerPool(orchestrator *Orchestrator, jobQueue *JobQueue, concurrency int) *WorkerPool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &WorkerPool{
+		orchestrator: orchestrator,
+		jobQueue:     jobQueue,
+		concurrency:  concurrency,
+	}
+}
+
+// Start launches the worker goroutines. They run until ctx is cancelled,
+// checking ctx between job claims so shutdown doesn't wait on an entire
+// pipeline run to drain.
+func (p *WorkerPool) Start(ctx context.Context) {
+	for i := 0; i < p.concurrency; i++ {
+		go p.runWorker(ctx)
+	}
+}
+
+func (p *WorkerPool) runWorker(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.claimAndProcess(ctx)
+		}
+	}
+}
+
+func (p *WorkerPool) claimAndProcess(ctx context.Context) {
+	job, err := p.jobQueue.ClaimNext()
+	if err != nil {
+		log.Printf("Worker: failed to claim job: %v", err)
+		return
+	}
+	if job == nil || ctx.Err() != nil {
+		return
+	}
+
+	if err := p.orchestrator.ProcessJob(job); err != nil {
+		log.Printf("Worker: job %d (run=%d, stage=%s) failed: %v", job.ID, job.PipelineRunID, job.Stage, err)
+
+		// A typed error that isn't marked Retryable (e.g. validation or
+		// not-found failures) won't succeed on a later attempt either, so
+		// dead-letter it immediately instead of burning MaxAttempts worth of
+		// backoff on a job that can never complete.
+		e, ok := errs.As(err)
+		forceDeadLetter := ok && !e.Retryable
+
+		if failErr := p.jobQueue.Fail(job, err, forceDeadLetter); failErr != nil {
+			log.Printf("Worker: failed to record job %d failure: %v", job.ID, failErr)
+			return
+		}
+
+		if forceDeadLetter || job.Attempts+1 >= job.MaxAttempts {
+			if dlErr := p.orchestrator.HandleJobDeadLetter(job, err); dlErr != nil {
+				log.Printf("Worker: failed to propagate dead-letter for job %d: %v", job.ID, dlErr)
+			}
+		}
+		return
+	}
+
+	if err := p.jobQueue.Complete(job.ID); err != nil {
+		log.Printf("Worker: failed to mark job %d complete: %v", job.ID, err)
+	}
+}
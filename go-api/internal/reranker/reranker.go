@@ -0,0 +1,88 @@
+// Package reranker implements the optional cross-encoder reranking stage
+// used by hybrid retrieval: Handler.hybridSearch POSTs the query alongside
+// its top candidate chunk texts to an HTTP service (e.g. bge-reranker) and
+// reorders those candidates by the returned relevance scores.
+package reranker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Client talks to a cross-encoder reranking HTTP service.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient builds a reranker Client from RERANKER_URL. It returns nil when
+// the variable isn't set, so reranking stays opt-in: callers can check for a
+// nil Client and skip the stage entirely instead of handling a disabled
+// client everywhere.
+func NewClient() *Client {
+	baseURL := os.Getenv("RERANKER_URL")
+	if baseURL == "" {
+		return nil
+	}
+
+	return &Client{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// rerankRequest is the payload sent to the cross-encoder service: a query
+// paired with the candidate texts to score against it.
+type rerankRequest struct {
+	Query      string   `json:"query"`
+	Candidates []string `json:"candidates"`
+}
+
+// rerankResponse carries one relevance score per candidate, in the same
+// order as the request's Candidates.
+type rerankResponse struct {
+	Scores []float32 `json:"scores"`
+}
+
+// Score sends the query and candidate texts to the reranker service and
+// returns one relevance score per candidate, in the same order as texts.
+func (c *Client) Score(query string, texts []string) ([]float32, error) {
+	reqBody := rerankRequest{Query: query, Candidates: texts}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rerank request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(
+		fmt.Sprintf("%s/rerank", c.baseURL),
+		"application/json",
+		bytes.NewBuffer(jsonData),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call reranker service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("reranker service returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var rerankResp rerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rerankResp); err != nil {
+		return nil, fmt.Errorf("failed to decode rerank response: %w", err)
+	}
+
+	if len(rerankResp.Scores) != len(texts) {
+		return nil, fmt.Errorf("reranker returned %d scores for %d candidates", len(rerankResp.Scores), len(texts))
+	}
+
+	return rerankResp.Scores, nil
+}
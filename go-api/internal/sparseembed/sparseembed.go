@@ -0,0 +1,101 @@
+// Package sparseembed implements the optional SPLADE-style sparse embedding
+// stage used by hybrid retrieval: Handler.hybridSearch POSTs the query to an
+// HTTP service and gets back a sparse (position, value) encoding it can run
+// as a native Milvus sparse-vector ANN query, instead of falling back to the
+// in-memory BM25 index (see vector.Store.FullTextSearch) for the sparse leg
+// of the fusion.
+package sparseembed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/vector"
+)
+
+// Client talks to a SPLADE-style sparse embedding HTTP service.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient builds a Client from EMBEDDING_SPARSE_URL. It returns nil when
+// the variable isn't set, so the sparse-embedding leg stays opt-in: callers
+// can check for a nil Client and fall back to BM25 instead of handling a
+// disabled client everywhere.
+func NewClient() *Client {
+	baseURL := os.Getenv("EMBEDDING_SPARSE_URL")
+	if baseURL == "" {
+		return nil
+	}
+
+	return &Client{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// embedRequest is the payload sent to the sparse embedding service.
+type embedRequest struct {
+	Texts []string `json:"texts"`
+}
+
+// sparseVector mirrors vector.SparseEmbedding's (position, value) pairs over
+// the wire.
+type sparseVector struct {
+	Positions []uint32  `json:"positions"`
+	Values    []float32 `json:"values"`
+}
+
+// embedResponse carries one sparse vector per input text, in the same order
+// as the request's Texts.
+type embedResponse struct {
+	Embeddings []sparseVector `json:"embeddings"`
+}
+
+// Embed returns one vector.SparseEmbedding per text, in the same order as
+// texts.
+func (c *Client) Embed(texts []string) ([]vector.SparseEmbedding, error) {
+	reqBody := embedRequest{Texts: texts}
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sparse embed request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(
+		fmt.Sprintf("%s/api/v1/sparse-embeddings", c.baseURL),
+		"application/json",
+		bytes.NewBuffer(jsonData),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call sparse embedding service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("sparse embedding service returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var embResp embedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("failed to decode sparse embed response: %w", err)
+	}
+
+	if len(embResp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("sparse embedding service returned %d vectors for %d texts", len(embResp.Embeddings), len(texts))
+	}
+
+	out := make([]vector.SparseEmbedding, len(embResp.Embeddings))
+	for i, v := range embResp.Embeddings {
+		out[i] = vector.SparseEmbedding{Positions: v.Positions, Values: v.Values}
+	}
+	return out, nil
+}
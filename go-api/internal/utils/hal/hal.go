@@ -0,0 +1,90 @@
+// Package hal provides minimal HAL+JSON (application/hal+json) helpers so
+// handlers can advertise `_links`/`_embedded` relations instead of clients
+// hardcoding URLs like /api/v1/pipeline/{id}/cancel.
+package hal
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Link is a single HAL link relation.
+type Link struct {
+	Href   string `json:"href"`
+	Method string `json:"method,omitempty"` // non-GET methods are called out explicitly since HAL has no verb convention
+}
+
+// Links is a set of relation-name -> Link, rendered as a response's `_links`.
+type Links map[string]Link
+
+// SendHAL writes body as JSON with a `_links` member merged in, giving every
+// HAL response the same shape regardless of handler.
+func SendHAL(c *fiber.Ctx, status int, body fiber.Map, links Links) error {
+	if body == nil {
+		body = fiber.Map{}
+	}
+	body["_links"] = links
+	return c.Status(status).JSON(body)
+}
+
+// PipelineLinks builds the link relations for a single pipeline run.
+func PipelineLinks(id uint) Links {
+	base := fmt.Sprintf("/api/v1/pipeline/%d", id)
+	return Links{
+		"self":    {Href: base + "/status"},
+		"status":  {Href: base + "/status"},
+		"events":  {Href: base + "/events"},
+		"logs":    {Href: base + "/logs"},
+		"results": {Href: base + "/results"},
+		"cancel":  {Href: base + "/cancel", Method: "POST"},
+		"resume":  {Href: base + "/resume", Method: "POST"},
+		"retry":   {Href: base + "/retry", Method: "POST"},
+	}
+}
+
+// DocumentLinks builds the link relations for a single document.
+func DocumentLinks(id uint) Links {
+	base := fmt.Sprintf("/api/v1/documents/%d", id)
+	return Links{
+		"self":   {Href: base},
+		"update": {Href: base, Method: "PUT"},
+		"delete": {Href: base, Method: "DELETE"},
+	}
+}
+
+// PaginationLinks builds next/prev relations for an offset-paginated
+// collection at basePath, omitting whichever end doesn't apply.
+func PaginationLinks(basePath string, skip, limit int, total int64) Links {
+	links := Links{
+		"self": {Href: fmt.Sprintf("%s?skip=%d&limit=%d", basePath, skip, limit)},
+	}
+
+	if int64(skip+limit) < total {
+		links["next"] = Link{Href: fmt.Sprintf("%s?skip=%d&limit=%d", basePath, skip+limit, limit)}
+	}
+	if skip > 0 {
+		prevSkip := skip - limit
+		if prevSkip < 0 {
+			prevSkip = 0
+		}
+		links["prev"] = Link{Href: fmt.Sprintf("%s?skip=%d&limit=%d", basePath, prevSkip, limit)}
+	}
+
+	return links
+}
+
+// CursorPaginationLinks builds self/next relations for a keyset-paginated
+// collection at basePath. There's no "prev" here: a keyset cursor only knows
+// how to move forward, unlike offset pagination's PaginationLinks.
+func CursorPaginationLinks(basePath string, cursor int64, limit int, nextCursor int64) Links {
+	links := Links{
+		"self": {Href: fmt.Sprintf("%s?cursor=%d&limit=%d", basePath, cursor, limit)},
+	}
+
+	if nextCursor != 0 {
+		links["next"] = Link{Href: fmt.Sprintf("%s?cursor=%d&limit=%d", basePath, nextCursor, limit)}
+	}
+
+	return links
+}
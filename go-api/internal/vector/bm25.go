@@ -0,0 +1,212 @@
+package vector
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// bm25K1 and bm25B are the standard Okapi BM25 tuning constants (term
+// frequency saturation and length normalization strength).
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// stopwords holds the English stopword list filtered out of chunk tokens so
+// they don't dominate document frequency stats.
+var stopwords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "by": {},
+	"for": {}, "from": {}, "has": {}, "he": {}, "in": {}, "is": {}, "it": {}, "its": {},
+	"of": {}, "on": {}, "or": {}, "that": {}, "the": {}, "this": {}, "to": {}, "was": {},
+	"were": {}, "will": {}, "with": {},
+}
+
+// tokenize lowercases text and splits it into unicode-aware word tokens,
+// dropping stopwords. It's shared by indexing and query-time scoring so both
+// sides agree on what counts as a term.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		token := strings.ToLower(f)
+		if _, stop := stopwords[token]; stop {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
+
+// bm25Doc is a single indexed chunk: its term frequencies and length, plus
+// enough of the original row to build a SearchResult without going back to
+// Milvus.
+type bm25Doc struct {
+	documentID int64
+	chunkIndex int64
+	chunkText  string
+	metadata   string
+	termFreq   map[string]int
+	length     int
+}
+
+// bm25Index is an in-memory inverted index of chunk tokens with
+// document-frequency and length-normalization stats, used to give the
+// Milvus-backed Store a lexical FullTextSearch that doesn't depend on
+// Milvus's own (dense-only) indexing. It's rebuilt from the chunks
+// collection on startup and kept in sync incrementally by AddChunks/
+// DeleteDocument, so it stays consistent with dense inserts without a
+// separate reindex step.
+type bm25Index struct {
+	mu sync.RWMutex
+
+	docs       map[int64]*bm25Doc // keyed by chunk id
+	docFreq    map[string]int     // term -> number of docs containing it
+	totalDocs  int
+	totalTerms int
+}
+
+func newBM25Index() *bm25Index {
+	return &bm25Index{
+		docs:    make(map[int64]*bm25Doc),
+		docFreq: make(map[string]int),
+	}
+}
+
+// add indexes a single chunk under its Milvus-assigned id, replacing any
+// previous entry for that id.
+func (b *bm25Index) add(chunkID, documentID, chunkIndex int64, chunkText, metadata string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.removeLocked(chunkID)
+
+	tokens := tokenize(chunkText)
+	termFreq := make(map[string]int, len(tokens))
+	for _, t := range tokens {
+		termFreq[t]++
+	}
+
+	for t := range termFreq {
+		b.docFreq[t]++
+	}
+
+	b.docs[chunkID] = &bm25Doc{
+		documentID: documentID,
+		chunkIndex: chunkIndex,
+		chunkText:  chunkText,
+		metadata:   metadata,
+		termFreq:   termFreq,
+		length:     len(tokens),
+	}
+	b.totalDocs++
+	b.totalTerms += len(tokens)
+}
+
+// removeDocument drops every indexed chunk belonging to documentID.
+func (b *bm25Index) removeDocument(documentID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for chunkID, doc := range b.docs {
+		if doc.documentID == documentID {
+			b.removeLocked(chunkID)
+		}
+	}
+}
+
+// remove drops a single indexed chunk by id, e.g. when UpsertDocument
+// deletes just the chunks whose fingerprint is no longer produced.
+func (b *bm25Index) remove(chunkID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(chunkID)
+}
+
+// removeLocked removes a single chunk id; callers must hold b.mu.
+func (b *bm25Index) removeLocked(chunkID int64) {
+	doc, ok := b.docs[chunkID]
+	if !ok {
+		return
+	}
+
+	for t := range doc.termFreq {
+		b.docFreq[t]--
+		if b.docFreq[t] <= 0 {
+			delete(b.docFreq, t)
+		}
+	}
+
+	b.totalDocs--
+	b.totalTerms -= doc.length
+	delete(b.docs, chunkID)
+}
+
+// search scores every indexed chunk against query using Okapi BM25 and
+// returns the top `limit` matches, best first.
+func (b *bm25Index) search(query string, limit int) ([]SearchResult, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 || b.totalDocs == 0 {
+		return nil, nil
+	}
+
+	avgDocLength := float64(b.totalTerms) / float64(b.totalDocs)
+
+	scores := make(map[int64]float32, len(b.docs))
+	for chunkID, doc := range b.docs {
+		var score float64
+		for _, term := range queryTerms {
+			df := b.docFreq[term]
+			if df == 0 {
+				continue
+			}
+			tf := float64(doc.termFreq[term])
+			if tf == 0 {
+				continue
+			}
+
+			idf := math.Log(1 + (float64(b.totalDocs)-float64(df)+0.5)/(float64(df)+0.5))
+			norm := tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgDocLength))
+			score += idf * norm
+		}
+
+		if score > 0 {
+			scores[chunkID] = float32(score)
+		}
+	}
+
+	ranked := make([]int64, 0, len(scores))
+	for chunkID := range scores {
+		ranked = append(ranked, chunkID)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	results := make([]SearchResult, 0, len(ranked))
+	for _, chunkID := range ranked {
+		doc := b.docs[chunkID]
+		results = append(results, SearchResult{
+			DocumentID: doc.documentID,
+			ChunkIndex: doc.chunkIndex,
+			ChunkText:  doc.chunkText,
+			Metadata:   doc.metadata,
+			Score:      scores[chunkID],
+		})
+	}
+
+	return results, nil
+}
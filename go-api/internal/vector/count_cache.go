@@ -0,0 +1,50 @@
+package vector
+
+import (
+	"sync"
+	"time"
+)
+
+// countCacheTTL bounds how stale ListDocuments' total count estimate can be.
+// count(*) is expensive enough on both backends (a Milvus query scan, a
+// Postgres sequential/index scan) that paying for it on every page request
+// isn't worth it for a number that's only ever shown as approximate.
+const countCacheTTL = 30 * time.Second
+
+// countCache memoizes a count(*)-style query per cache key (typically
+// tenant+filter) for countCacheTTL, shared by MilvusClient and PGVectorStore.
+type countCache struct {
+	mu      sync.Mutex
+	entries map[string]countCacheEntry
+}
+
+type countCacheEntry struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func newCountCache() *countCache {
+	return &countCache{entries: make(map[string]countCacheEntry)}
+}
+
+// get returns the cached count for key if still fresh, otherwise calls
+// compute, caches its result for countCacheTTL, and returns that instead.
+func (c *countCache) get(key string, compute func() (int64, error)) (int64, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && time.Now().Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.count, nil
+	}
+	c.mu.Unlock()
+
+	count, err := compute()
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = countCacheEntry{count: count, expiresAt: time.Now().Add(countCacheTTL)}
+	c.mu.Unlock()
+
+	return count, nil
+}
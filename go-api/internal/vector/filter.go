@@ -0,0 +1,141 @@
+package vector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+)
+
+// searchFilter accumulates the scalar constraints built up by SearchOptions
+// into a single Milvus boolean expression.
+type searchFilter struct {
+	expr        string
+	documentIDs []int64
+	docType     string
+	from, to    int64
+	tenants     []string
+}
+
+// SearchOption narrows a MilvusClient.Search/HybridSearch call to a subset
+// of chunks via a Milvus scalar filter expression (e.g.
+// `document_id in [1,2,3] && doc_type == "pdf"`), applied server-side
+// alongside the ANN query instead of filtering results afterward.
+type SearchOption func(*searchFilter)
+
+// WithFilter ANDs a raw Milvus boolean expression onto the search, e.g.
+// `WithFilter("created_at > 1700000000")`.
+func WithFilter(expr string) SearchOption {
+	return func(f *searchFilter) { f.expr = expr }
+}
+
+// WithDocumentIDs restricts the search to chunks belonging to one of ids.
+func WithDocumentIDs(ids []int64) SearchOption {
+	return func(f *searchFilter) { f.documentIDs = ids }
+}
+
+// WithDocType restricts the search to chunks whose parent document has
+// doc_type == s.
+func WithDocType(s string) SearchOption {
+	return func(f *searchFilter) { f.docType = s }
+}
+
+// WithTimeRange restricts the search to chunks whose parent document's
+// created_at (unix seconds) falls within [from, to].
+func WithTimeRange(from, to int64) SearchOption {
+	return func(f *searchFilter) { f.from = from; f.to = to }
+}
+
+// WithTenants scopes the search to one or more tenant partitions (see
+// MilvusClient.EnsurePartition), letting admins run cross-tenant queries by
+// naming more than one. With no WithTenants option, Search runs across every
+// partition, matching the pre-tenancy behavior. Ignored by backends without a
+// partition primitive.
+func WithTenants(tenantIDs []string) SearchOption {
+	return func(f *searchFilter) { f.tenants = tenantIDs }
+}
+
+// buildExpr applies opts and renders the resulting constraints as a single
+// Milvus boolean expression, ANDing every clause that was set. Returns ""
+// (no filter) if no option narrowed the search.
+func buildExpr(opts ...SearchOption) string {
+	var f searchFilter
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	var clauses []string
+	if f.expr != "" {
+		clauses = append(clauses, "("+f.expr+")")
+	}
+	if len(f.documentIDs) > 0 {
+		ids := make([]string, len(f.documentIDs))
+		for i, id := range f.documentIDs {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		clauses = append(clauses, fmt.Sprintf("document_id in [%s]", strings.Join(ids, ",")))
+	}
+	if f.docType != "" {
+		clauses = append(clauses, fmt.Sprintf("doc_type == %q", f.docType))
+	}
+	if f.from != 0 || f.to != 0 {
+		clauses = append(clauses, fmt.Sprintf("created_at >= %d && created_at <= %d", f.from, f.to))
+	}
+
+	return strings.Join(clauses, " && ")
+}
+
+// partitionsFromOptions applies opts and returns the tenant partition names
+// set by WithTenants, or nil if none was given (meaning "every partition" to
+// Milvus's client.Search).
+func partitionsFromOptions(opts ...SearchOption) []string {
+	var f searchFilter
+	for _, opt := range opts {
+		opt(&f)
+	}
+	return f.tenants
+}
+
+// ListFilter narrows ListDocuments to documents matching every field set;
+// zero-valued fields impose no constraint. Unlike SearchOption, ListDocuments
+// has no ANN query to attach functional options to, so this is a plain
+// struct passed alongside the keyset cursor.
+type ListFilter struct {
+	DocType      string
+	CreatedAfter int64 // unix seconds; 0 means no lower bound
+	TitlePrefix  string
+}
+
+// buildListExpr renders f as a Milvus boolean expression ANDed with the
+// `id > cursor` keyset condition. DocType/TitlePrefix reach here straight
+// from ListDocuments's query-string filter, so - same reasoning as
+// MilvusClient.findDocumentForUpsert - %q alone isn't a safe escape against
+// Milvus's expression grammar; reject rather than risk a value breaking out
+// of the string literal.
+func buildListExpr(cursor int64, f ListFilter) (string, error) {
+	clauses := []string{fmt.Sprintf("id > %d", cursor)}
+	if f.DocType != "" {
+		if strings.ContainsAny(f.DocType, `"\`) {
+			return "", errs.New(errs.CodeValidation, "doc_type must not contain quote or backslash characters")
+		}
+		clauses = append(clauses, fmt.Sprintf("doc_type == %q", f.DocType))
+	}
+	if f.CreatedAfter != 0 {
+		clauses = append(clauses, fmt.Sprintf("created_at > %d", f.CreatedAfter))
+	}
+	if f.TitlePrefix != "" {
+		if strings.ContainsAny(f.TitlePrefix, `"\`) {
+			return "", errs.New(errs.CodeValidation, "title_prefix must not contain quote or backslash characters")
+		}
+		clauses = append(clauses, fmt.Sprintf("title like %q", f.TitlePrefix+"%"))
+	}
+	return strings.Join(clauses, " && "), nil
+}
+
+// cacheKey renders f as a stable string for countCache's key. The cursor
+// isn't part of it: a total-count estimate doesn't depend on which page the
+// caller is on, only on the filter.
+func (f ListFilter) cacheKey() string {
+	return fmt.Sprintf("doc_type=%s&created_after=%d&title_prefix=%s", f.DocType, f.CreatedAfter, f.TitlePrefix)
+}
@@ -2,12 +2,20 @@ package vector
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -16,7 +24,9 @@ const (
 )
 
 type MilvusClient struct {
-	client client.Client
+	client     client.Client
+	bm25       *bm25Index
+	listCounts *countCache
 }
 
 type Chunk struct {
@@ -25,6 +35,38 @@ type Chunk struct {
 	ChunkIndex int64
 	ChunkText  string
 	Embedding  []float32
+
+	// Metadata is a JSON-encoded object carrying chunking provenance (byte
+	// offsets into the source document, Markdown heading breadcrumb) so
+	// retrieval can cite back to where a chunk came from. Empty for chunks
+	// produced before this was tracked.
+	Metadata string
+
+	// Sparse is this chunk's lexical embedding (e.g. SPLADE or a
+	// BM25-derived encoding) used by HybridSearch's sparse ANN subquery.
+	// Its zero value is a valid empty sparse vector.
+	Sparse SparseEmbedding
+
+	// DocType and CreatedAt are copied from the parent Document at ingest
+	// time so Search/HybridSearch can filter by them (WithDocType,
+	// WithTimeRange) without a join back to the documents collection.
+	DocType   string
+	CreatedAt int64
+
+	// Fingerprint identifies this chunk's content for UpsertDocument's
+	// idempotent re-ingest: chunks whose fingerprint already exists aren't
+	// re-inserted, and existing chunks whose fingerprint is no longer
+	// produced are deleted. Computed by chunkFingerprint if left empty.
+	Fingerprint string
+}
+
+// chunkFingerprint derives a stable identity for a chunk's content so
+// UpsertDocument can tell which chunks of a re-ingested document are
+// unchanged, new, or gone: sha256 of "documentID|chunkIndex|chunkText", hex
+// encoded.
+func chunkFingerprint(documentID, chunkIndex int64, chunkText string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%d|%s", documentID, chunkIndex, chunkText)))
+	return hex.EncodeToString(sum[:])
 }
 
 func Initialize(url, token string) (*MilvusClient, error) {
@@ -39,7 +81,7 @@ func Initialize(url, token string) (*MilvusClient, error) {
 		return nil, fmt.Errorf("failed to connect to Milvus: %w", err)
 	}
 
-	return &MilvusClient{client: c}, nil
+	return &MilvusClient{client: c, bm25: newBM25Index(), listCounts: newCountCache()}, nil
 }
 
 func (m *MilvusClient) Close() {
@@ -48,29 +90,141 @@ func (m *MilvusClient) Close() {
 	}
 }
 
-func (m *MilvusClient) AddChunks(chunks []Chunk) error {
+// FlushChunks seals the chunks collection, forcing buffered inserts into a
+// searchable segment. Useful after a large batch ingest so callers can rely
+// on immediately querying what they just wrote.
+func (m *MilvusClient) FlushChunks() error {
+	ctx := context.Background()
+
+	if err := m.client.Flush(ctx, CollectionName, false); err != nil {
+		return fmt.Errorf("failed to flush chunks collection: %w", err)
+	}
+
+	return nil
+}
+
+// EnsurePartition creates tenantID's partition on both the chunks and
+// documents collections if it doesn't already exist, so AddChunks/
+// CreateDocument/Search can target it. A "" tenantID is the default
+// partition, which always exists, so this is a no-op for it.
+func (m *MilvusClient) EnsurePartition(tenantID string) error {
+	if tenantID == "" {
+		return nil
+	}
+	ctx := context.Background()
+
+	for _, collection := range []string{CollectionName, DocumentsCollection} {
+		has, err := m.client.HasPartition(ctx, collection, tenantID)
+		if err != nil {
+			return errs.Wrapf(errs.CodeVectorStore, err, "failed to check partition %q on %s", tenantID, collection)
+		}
+		if !has {
+			if err := m.client.CreatePartition(ctx, collection, tenantID); err != nil {
+				return errs.Wrapf(errs.CodeVectorStore, err, "failed to create partition %q on %s", tenantID, collection)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DropPartition removes tenantID's partition (and everything stored in it)
+// from both collections in O(1), for tenant offboarding.
+func (m *MilvusClient) DropPartition(tenantID string) error {
+	if tenantID == "" {
+		return errs.New(errs.CodeValidation, "cannot drop the default partition")
+	}
+	ctx := context.Background()
+
+	for _, collection := range []string{CollectionName, DocumentsCollection} {
+		if err := m.client.DropPartition(ctx, collection, tenantID); err != nil {
+			return errs.Wrapf(errs.CodeVectorStore, err, "failed to drop partition %q on %s", tenantID, collection)
+		}
+	}
+
+	return nil
+}
+
+// partitionList renders a single tenantID as the []string partitions
+// argument Query/Search expect: nil (every partition) for the "" default
+// tenant, or a one-element slice naming tenantID's partition otherwise.
+func partitionList(tenantID string) []string {
+	if tenantID == "" {
+		return nil
+	}
+	return []string{tenantID}
+}
+
+// wrapWriteError wraps a failed write RPC (Insert) as *errs.Error, marking it
+// Retryable when the underlying gRPC status code indicates a transient
+// condition (Unavailable - connection refused/reset, DeadlineExceeded -
+// timeout, ResourceExhausted - overloaded) rather than a request the caller
+// made wrong, which Milvus reports via codes like InvalidArgument or
+// AlreadyExists. err that isn't a gRPC status at all (e.g. a local encoding
+// failure before the RPC was even sent) is treated as terminal, the same as
+// an unrecognized code - only codes known to be transient get retried.
+func wrapWriteError(message string, err error) error {
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+			return errs.WrapRetryable(errs.CodeVectorStore, message, err)
+		}
+	}
+	return errs.Wrap(errs.CodeVectorStore, message, err)
+}
+
+func (m *MilvusClient) AddChunks(chunks []Chunk, tenantID string) error {
 	ctx := context.Background()
 
 	documentIDs := make([]int64, len(chunks))
 	chunkIndices := make([]int64, len(chunks))
 	chunkTexts := make([]string, len(chunks))
+	metadata := make([]string, len(chunks))
+	docTypes := make([]string, len(chunks))
+	createdAts := make([]int64, len(chunks))
 	embeddings := make([][]float32, len(chunks))
+	sparseEmbeddings := make([]entity.SparseEmbedding, len(chunks))
+	fingerprints := make([]string, len(chunks))
 
 	for i, chunk := range chunks {
 		documentIDs[i] = chunk.DocumentID
 		chunkIndices[i] = chunk.ChunkIndex
 		chunkTexts[i] = chunk.ChunkText
+		metadata[i] = chunk.Metadata
+		docTypes[i] = chunk.DocType
+		createdAts[i] = chunk.CreatedAt
 		embeddings[i] = chunk.Embedding
+		fingerprints[i] = chunk.Fingerprint
+		if fingerprints[i] == "" {
+			fingerprints[i] = chunkFingerprint(chunk.DocumentID, chunk.ChunkIndex, chunk.ChunkText)
+		}
+
+		sparseVec, err := entity.NewSliceSparseEmbedding(chunk.Sparse.Positions, chunk.Sparse.Values)
+		if err != nil {
+			return errs.Wrap(errs.CodeVectorStore, "failed to encode sparse embedding", err)
+		}
+		sparseEmbeddings[i] = sparseVec
 	}
 
 	documentIDCol := entity.NewColumnInt64("document_id", documentIDs)
 	chunkIndexCol := entity.NewColumnInt64("chunk_index", chunkIndices)
 	chunkTextCol := entity.NewColumnVarChar("chunk_text", chunkTexts)
+	metadataCol := entity.NewColumnVarChar("metadata", metadata)
+	docTypeCol := entity.NewColumnVarChar("doc_type", docTypes)
+	createdAtCol := entity.NewColumnInt64("created_at", createdAts)
 	embeddingCol := entity.NewColumnFloatVector("embedding", Dim, embeddings)
+	sparseCol := entity.NewColumnSparseVectors("sparse_embedding", sparseEmbeddings)
+	fingerprintCol := entity.NewColumnVarChar("fingerprint", fingerprints)
 
-	_, err := m.client.Insert(ctx, CollectionName, "", documentIDCol, chunkIndexCol, chunkTextCol, embeddingCol)
+	ids, err := m.client.Insert(ctx, CollectionName, tenantID, documentIDCol, chunkIndexCol, chunkTextCol, metadataCol, docTypeCol, createdAtCol, embeddingCol, sparseCol, fingerprintCol)
 	if err != nil {
-		return fmt.Errorf("failed to insert chunks: %w", err)
+		return wrapWriteError("failed to insert chunks", err)
+	}
+
+	if idCol, ok := ids.(*entity.ColumnInt64); ok {
+		for i, chunkID := range idCol.Data() {
+			m.bm25.add(chunkID, chunks[i].DocumentID, chunks[i].ChunkIndex, chunks[i].ChunkText, chunks[i].Metadata)
+		}
 	}
 
 	return nil
@@ -78,21 +232,45 @@ func (m *MilvusClient) AddChunks(chunks []Chunk) error {
 
 type SearchResult struct {
 	DocumentID int64
+	ChunkIndex int64
 	ChunkText  string
 	Score      float32
+	Metadata   string // JSON-encoded chunking provenance; see Chunk.Metadata
 }
 
-func (m *MilvusClient) Search(queryVector []float32, limit int, minScore float64) ([]SearchResult, error) {
+func (m *MilvusClient) Search(queryVector []float32, limit int, minScore float64, opts ...SearchOption) ([]SearchResult, error) {
 	ctx := context.Background()
 
-	sp, _ := entity.NewIndexFlatSearchParam() // AutoIndex uses default search params usually, or we can use specific ones if we knew the index type. AutoIndex is safe.
+	results, err := m.annSearch(ctx, "embedding", entity.FloatVector(queryVector), entity.COSINE, limit, opts...)
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to search", err)
+	}
+
+	filtered := results[:0]
+	for _, r := range results {
+		if r.Score >= float32(minScore) {
+			filtered = append(filtered, r)
+		}
+	}
+
+	return filtered, nil
+}
+
+// SearchDocuments runs a dense similarity search over the documents
+// collection's document-level embedding (see Document.Embedding), returning
+// the closest documents best-score-first. This is the coarse half of
+// coarse-to-fine RAG: pick the top documents here, then Search/HybridSearch
+// within just their chunks via WithDocumentIDs.
+func (m *MilvusClient) SearchDocuments(queryVector []float32, limit int, opts ...SearchOption) ([]DocumentSearchResult, error) {
+	ctx := context.Background()
+	sp, _ := entity.NewIndexFlatSearchParam()
 
 	searchResult, err := m.client.Search(
 		ctx,
-		CollectionName,
-		[]string{},
-		"",
-		[]string{"document_id", "chunk_text"},
+		DocumentsCollection,
+		partitionsFromOptions(opts...),
+		buildExpr(opts...),
+		[]string{"id", "title", "content", "source_url", "doc_type", "metadata", "created_at"},
 		[]entity.Vector{entity.FloatVector(queryVector)},
 		"embedding",
 		entity.COSINE,
@@ -100,17 +278,82 @@ func (m *MilvusClient) Search(queryVector []float32, limit int, minScore float64
 		sp,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search: %w", err)
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to search documents", err)
 	}
 
-	var results []SearchResult
+	var results []DocumentSearchResult
 	for _, result := range searchResult {
 		for i := 0; i < result.ResultCount; i++ {
-			score := result.Scores[i]
-			if score < float32(minScore) {
-				continue
-			}
+			results = append(results, DocumentSearchResult{
+				Document: Document{
+					ID:        mustGetInt64(result.Fields, "id", i),
+					Title:     mustGetString(result.Fields, "title", i),
+					Content:   mustGetString(result.Fields, "content", i),
+					SourceURL: mustGetString(result.Fields, "source_url", i),
+					DocType:   mustGetString(result.Fields, "doc_type", i),
+					Metadata:  mustGetString(result.Fields, "metadata", i),
+					CreatedAt: mustGetInt64(result.Fields, "created_at", i),
+				},
+				Score: result.Scores[i],
+			})
+		}
+	}
+
+	return results, nil
+}
 
+// HybridSearch issues a dense ANN subquery against the `embedding` field and
+// a sparse ANN subquery against the `sparse_embedding` field, then fuses the
+// two ranked lists with Reciprocal Rank Fusion (see rrfFuse), so callers can
+// mix a dense embedding with a lexical one (e.g. SPLADE or a BM25-derived
+// encoding) in a single ranked result set.
+func (m *MilvusClient) HybridSearch(denseQuery []float32, sparseQuery SparseEmbedding, limit int, rrfK int, opts ...SearchOption) ([]SearchResult, error) {
+	ctx := context.Background()
+
+	dense, err := m.annSearch(ctx, "embedding", entity.FloatVector(denseQuery), entity.COSINE, limit, opts...)
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed dense ANN subquery", err)
+	}
+
+	sparseVec, err := entity.NewSliceSparseEmbedding(sparseQuery.Positions, sparseQuery.Values)
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to encode sparse query", err)
+	}
+
+	sparse, err := m.annSearch(ctx, "sparse_embedding", entity.SparseFloatVector(sparseVec), entity.IP, limit, opts...)
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed sparse ANN subquery", err)
+	}
+
+	return rrfFuse(dense, sparse, rrfK, limit), nil
+}
+
+// annSearch runs a single ANN subquery against fieldName, narrowed by
+// whatever scalar filter opts build (see buildExpr), and decodes the
+// document_id/chunk_index/chunk_text/metadata output fields into
+// SearchResults, best score first.
+func (m *MilvusClient) annSearch(ctx context.Context, fieldName string, queryVector entity.Vector, metric entity.MetricType, limit int, opts ...SearchOption) ([]SearchResult, error) {
+	sp, _ := entity.NewIndexFlatSearchParam() // AutoIndex uses default search params usually, or we can use specific ones if we knew the index type. AutoIndex is safe.
+
+	searchResult, err := m.client.Search(
+		ctx,
+		CollectionName,
+		partitionsFromOptions(opts...),
+		buildExpr(opts...),
+		[]string{"document_id", "chunk_index", "chunk_text", "metadata"},
+		[]entity.Vector{queryVector},
+		fieldName,
+		metric,
+		limit,
+		sp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, result := range searchResult {
+		for i := 0; i < result.ResultCount; i++ {
 			docID, err := result.Fields.GetColumn("document_id").Get(i)
 			if err != nil {
 				log.Printf("Error getting document_id: %v", err)
@@ -123,10 +366,26 @@ func (m *MilvusClient) Search(queryVector []float32, limit int, minScore float64
 				continue
 			}
 
+			var chunkIndex int64
+			if idxCol := result.Fields.GetColumn("chunk_index"); idxCol != nil {
+				if v, err := idxCol.Get(i); err == nil {
+					chunkIndex, _ = v.(int64)
+				}
+			}
+
+			var metadata string
+			if metaCol := result.Fields.GetColumn("metadata"); metaCol != nil {
+				if v, err := metaCol.Get(i); err == nil {
+					metadata, _ = v.(string)
+				}
+			}
+
 			results = append(results, SearchResult{
 				DocumentID: docID.(int64),
+				ChunkIndex: chunkIndex,
 				ChunkText:  chunkText.(string),
-				Score:      score,
+				Score:      result.Scores[i],
+				Metadata:   metadata,
 			})
 		}
 	}
@@ -143,6 +402,24 @@ type Document struct {
 	DocType   string `json:"doc_type"`
 	Metadata  string `json:"metadata"` // JSON string
 	CreatedAt int64  `json:"created_at"`
+
+	// Embedding is a document-level vector (e.g. over title+summary) stored
+	// in the documents collection's "embedding" field and queried by
+	// SearchDocuments. Callers that don't need document-level search (or
+	// backends without a coarse index) may leave it nil.
+	Embedding []float32 `json:"-"`
+
+	// ExternalID is an optional caller-assigned identifier (e.g. a CMS page
+	// ID) UpsertDocument can match an existing document by, for callers
+	// whose content doesn't have a stable SourceURL.
+	ExternalID string `json:"external_id,omitempty"`
+}
+
+// DocumentSearchResult pairs a Document with its similarity score from
+// SearchDocuments.
+type DocumentSearchResult struct {
+	Document
+	Score float32
 }
 
 const DocumentsCollection = "documents"
@@ -160,6 +437,30 @@ func (m *MilvusClient) EnsureCollections() error {
 		return err
 	}
 
+	// Rebuild the in-memory BM25 index from whatever chunks already exist,
+	// so a restart doesn't lose lexical search over previously-ingested
+	// documents.
+	if err := m.reindexBM25(ctx); err != nil {
+		log.Printf("Warning: failed to rebuild BM25 index: %v", err)
+	}
+
+	return nil
+}
+
+// reindexBM25 loads every chunk currently in Milvus into the in-memory BM25
+// index. Called once at startup; incremental updates after that happen in
+// AddChunks/DeleteDocument.
+func (m *MilvusClient) reindexBM25(ctx context.Context) error {
+	res, err := m.client.Query(ctx, CollectionName, []string{}, "id > 0", []string{"id", "document_id", "chunk_index", "chunk_text", "metadata"})
+	if err != nil {
+		return fmt.Errorf("failed to query chunks for BM25 reindex: %w", err)
+	}
+
+	count := res.GetColumn("id").Len()
+	for i := 0; i < count; i++ {
+		m.bm25.add(mustGetInt64(res, "id", i), mustGetInt64(res, "document_id", i), mustGetInt64(res, "chunk_index", i), mustGetString(res, "chunk_text", i), mustGetString(res, "metadata", i))
+	}
+
 	return nil
 }
 
@@ -196,6 +497,29 @@ func (m *MilvusClient) ensureChunksCollection(ctx context.Context) error {
 						entity.TypeParamMaxLength: "65535",
 					},
 				},
+				{
+					Name:     "metadata",
+					DataType: entity.FieldTypeVarChar,
+					TypeParams: map[string]string{
+						entity.TypeParamMaxLength: "65535",
+					},
+				},
+				{
+					// Copied from the parent Document at ingest time so
+					// Search/HybridSearch can filter with WithDocType without
+					// joining back to the documents collection.
+					Name:     "doc_type",
+					DataType: entity.FieldTypeVarChar,
+					TypeParams: map[string]string{
+						entity.TypeParamMaxLength: "64",
+					},
+				},
+				{
+					// Copied from the parent Document at ingest time for
+					// WithTimeRange filtering.
+					Name:     "created_at",
+					DataType: entity.FieldTypeInt64,
+				},
 				{
 					Name:     "embedding",
 					DataType: entity.FieldTypeFloatVector,
@@ -203,6 +527,21 @@ func (m *MilvusClient) ensureChunksCollection(ctx context.Context) error {
 						entity.TypeParamDim: fmt.Sprintf("%d", Dim),
 					},
 				},
+				{
+					Name:     "sparse_embedding",
+					DataType: entity.FieldTypeSparseFloatVector,
+				},
+				{
+					// sha256(document_id|chunk_index|chunk_text); see
+					// chunkFingerprint and UpsertDocument. Lets a re-ingest
+					// diff against what's already stored instead of
+					// deleting and recreating every chunk.
+					Name:     "fingerprint",
+					DataType: entity.FieldTypeVarChar,
+					TypeParams: map[string]string{
+						entity.TypeParamMaxLength: "64",
+					},
+				},
 			},
 		}
 
@@ -220,6 +559,46 @@ func (m *MilvusClient) ensureChunksCollection(ctx context.Context) error {
 			return fmt.Errorf("failed to create index: %w", err)
 		}
 
+		// Create an inverted index on sparse_embedding (IP metric) so
+		// HybridSearch's sparse ANN subquery doesn't fall back to a brute-force
+		// scan.
+		sparseIdx, err := entity.NewIndexSparseInverted(entity.IP, 0.2)
+		if err != nil {
+			return fmt.Errorf("failed to create sparse index definition: %w", err)
+		}
+
+		if err := m.client.CreateIndex(ctx, CollectionName, "sparse_embedding", sparseIdx, false); err != nil {
+			return fmt.Errorf("failed to create sparse index: %w", err)
+		}
+
+		// Scalar indexes on doc_type/created_at so WithDocType/WithTimeRange
+		// filters don't fall back to a brute-force scan.
+		docTypeIdx, err := entity.NewIndexInverted()
+		if err != nil {
+			return fmt.Errorf("failed to create doc_type index definition: %w", err)
+		}
+		if err := m.client.CreateIndex(ctx, CollectionName, "doc_type", docTypeIdx, false); err != nil {
+			return fmt.Errorf("failed to create doc_type index: %w", err)
+		}
+
+		createdAtIdx, err := entity.NewIndexInverted()
+		if err != nil {
+			return fmt.Errorf("failed to create created_at index definition: %w", err)
+		}
+		if err := m.client.CreateIndex(ctx, CollectionName, "created_at", createdAtIdx, false); err != nil {
+			return fmt.Errorf("failed to create created_at index: %w", err)
+		}
+
+		// Scalar index on fingerprint so UpsertDocument's `fingerprint in
+		// [...]` lookups and deletes don't fall back to a brute-force scan.
+		fingerprintIdx, err := entity.NewIndexInverted()
+		if err != nil {
+			return fmt.Errorf("failed to create fingerprint index definition: %w", err)
+		}
+		if err := m.client.CreateIndex(ctx, CollectionName, "fingerprint", fingerprintIdx, false); err != nil {
+			return fmt.Errorf("failed to create fingerprint index: %w", err)
+		}
+
 		// Load collection
 		if err := m.client.LoadCollection(ctx, CollectionName, false); err != nil {
 			return fmt.Errorf("failed to load collection: %w", err)
@@ -286,10 +665,25 @@ func (m *MilvusClient) ensureDocumentsCollection(ctx context.Context) error {
 					DataType: entity.FieldTypeInt64,
 				},
 				{
-					Name:     "dummy_vector",
+					// A title+summary embedding so the documents collection
+					// itself is searchable (see SearchDocuments), instead of
+					// carrying a zero-valued vector purely to satisfy
+					// Milvus's "collection must have an indexed vector field
+					// to load" requirement.
+					Name:     "embedding",
 					DataType: entity.FieldTypeFloatVector,
 					TypeParams: map[string]string{
-						entity.TypeParamDim: "4",
+						entity.TypeParamDim: fmt.Sprintf("%d", Dim),
+					},
+				},
+				{
+					// Optional caller-assigned identifier UpsertDocument can
+					// match an existing document by, for callers without a
+					// stable source_url. See Document.ExternalID.
+					Name:     "external_id",
+					DataType: entity.FieldTypeVarChar,
+					TypeParams: map[string]string{
+						entity.TypeParamMaxLength: "256",
 					},
 				},
 			},
@@ -299,16 +693,26 @@ func (m *MilvusClient) ensureDocumentsCollection(ctx context.Context) error {
 			return fmt.Errorf("failed to create documents collection: %w", err)
 		}
 
-		// Create index on dummy_vector (required for loading)
-		idx, err := entity.NewIndexAUTOINDEX(entity.L2)
+		// Create index on embedding
+		idx, err := entity.NewIndexAUTOINDEX(entity.COSINE)
 		if err != nil {
 			return fmt.Errorf("failed to create index definition for documents: %w", err)
 		}
 
-		if err := m.client.CreateIndex(ctx, DocumentsCollection, "dummy_vector", idx, false); err != nil {
+		if err := m.client.CreateIndex(ctx, DocumentsCollection, "embedding", idx, false); err != nil {
 			return fmt.Errorf("failed to create index for documents: %w", err)
 		}
 
+		// Scalar index on external_id so UpsertDocument's lookup doesn't
+		// fall back to a brute-force scan.
+		externalIDIdx, err := entity.NewIndexInverted()
+		if err != nil {
+			return fmt.Errorf("failed to create external_id index definition: %w", err)
+		}
+		if err := m.client.CreateIndex(ctx, DocumentsCollection, "external_id", externalIDIdx, false); err != nil {
+			return fmt.Errorf("failed to create external_id index: %w", err)
+		}
+
 		if err := m.client.LoadCollection(ctx, DocumentsCollection, false); err != nil {
 			return fmt.Errorf("failed to load documents collection: %w", err)
 		}
@@ -316,15 +720,16 @@ func (m *MilvusClient) ensureDocumentsCollection(ctx context.Context) error {
 	return nil
 }
 
-// CreateDocument creates a new document in Milvus and returns its ID
-func (m *MilvusClient) CreateDocument(doc *Document) (int64, error) {
+// CreateDocument creates a new document in tenantID's partition in Milvus
+// and returns its ID.
+func (m *MilvusClient) CreateDocument(doc *Document, tenantID string) (int64, error) {
 	ctx := context.Background()
 
 	// Check for duplicates by title
 	// Query signature: ctx, collection, partitions, expr, outputFields
-	existing, err := m.client.Query(ctx, DocumentsCollection, []string{}, fmt.Sprintf("title == \"%s\"", doc.Title), []string{"id"})
+	existing, err := m.client.Query(ctx, DocumentsCollection, partitionList(tenantID), fmt.Sprintf("title == \"%s\"", doc.Title), []string{"id"})
 	if err == nil && existing.GetColumn("id").Len() > 0 {
-		return 0, fmt.Errorf("duplicate key value: document with title '%s' already exists", doc.Title)
+		return 0, errs.Newf(errs.CodeDuplicate, "document with title '%s' already exists", doc.Title)
 	}
 
 	titleCol := entity.NewColumnVarChar("title", []string{doc.Title})
@@ -334,43 +739,50 @@ func (m *MilvusClient) CreateDocument(doc *Document) (int64, error) {
 	metadataCol := entity.NewColumnVarChar("metadata", []string{doc.Metadata})
 	createdAtCol := entity.NewColumnInt64("created_at", []int64{time.Now().Unix()})
 
-	// Dummy vector
-	dummyVector := []float32{0.0, 0.0, 0.0, 0.0}
-	dummyVectorCol := entity.NewColumnFloatVector("dummy_vector", 4, [][]float32{dummyVector})
+	// A caller that hasn't computed a document-level embedding yet (e.g.
+	// over title+summary) still has to satisfy the schema's non-nullable
+	// vector field; fall back to the zero vector so it's excluded from
+	// SearchDocuments results by similarity alone.
+	embedding := doc.Embedding
+	if len(embedding) == 0 {
+		embedding = make([]float32, Dim)
+	}
+	embeddingCol := entity.NewColumnFloatVector("embedding", Dim, [][]float32{embedding})
+	externalIDCol := entity.NewColumnVarChar("external_id", []string{doc.ExternalID})
 
 	// ID is AutoID, so we don't pass it.
 	// However, Milvus Insert returns the generated IDs.
-	cols := []entity.Column{titleCol, contentCol, sourceURLCol, docTypeCol, metadataCol, createdAtCol, dummyVectorCol}
+	cols := []entity.Column{titleCol, contentCol, sourceURLCol, docTypeCol, metadataCol, createdAtCol, embeddingCol, externalIDCol}
 
-	ids, err := m.client.Insert(ctx, DocumentsCollection, "", cols...)
+	ids, err := m.client.Insert(ctx, DocumentsCollection, tenantID, cols...)
 	if err != nil {
-		return 0, fmt.Errorf("failed to insert document: %w", err)
+		return 0, wrapWriteError("failed to insert document", err)
 	}
 
 	if ids.Len() == 0 {
-		return 0, fmt.Errorf("failed to insert document: no ID returned")
+		return 0, errs.New(errs.CodeVectorStore, "failed to insert document: no ID returned")
 	}
 
 	// Assuming int64 ID
 	idCol, ok := ids.(*entity.ColumnInt64)
 	if !ok {
-		return 0, fmt.Errorf("unexpected ID type returned")
+		return 0, errs.New(errs.CodeVectorStore, "unexpected ID type returned")
 	}
 
 	return idCol.Data()[0], nil
 }
 
-// GetDocument retrieves a document by ID
-func (m *MilvusClient) GetDocument(id int64) (*Document, error) {
+// GetDocument retrieves a document by ID from tenantID's partition.
+func (m *MilvusClient) GetDocument(id int64, tenantID string) (*Document, error) {
 	ctx := context.Background()
 
-	res, err := m.client.Query(ctx, DocumentsCollection, []string{}, fmt.Sprintf("id == %d", id), []string{"id", "title", "content", "source_url", "doc_type", "metadata", "created_at"})
+	res, err := m.client.Query(ctx, DocumentsCollection, partitionList(tenantID), fmt.Sprintf("id == %d", id), []string{"id", "title", "content", "source_url", "doc_type", "metadata", "created_at"})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get document: %w", err)
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to get document", err)
 	}
 
 	if res.GetColumn("id").Len() == 0 {
-		return nil, fmt.Errorf("document not found")
+		return nil, errs.Newf(errs.CodeNotFound, "document %d not found", id)
 	}
 
 	doc := &Document{
@@ -386,18 +798,73 @@ func (m *MilvusClient) GetDocument(id int64) (*Document, error) {
 	return doc, nil
 }
 
-// ListDocuments lists documents with pagination
-func (m *MilvusClient) ListDocuments(limit, offset int) ([]Document, int64, error) {
+// GetDocumentsByIDs batch-fetches every document in ids from tenantID's
+// partition with a single "id in [...]" Query, instead of one GetDocument
+// round-trip per id - the N+1 pattern semanticSearch used to hit when
+// hydrating a page of chunk hits. Missing ids are simply absent from the
+// returned map rather than an error, since a chunk whose document was
+// deleted between the ANN search and this call shouldn't fail the whole
+// request.
+func (m *MilvusClient) GetDocumentsByIDs(ids []int64, tenantID string) (map[int64]*Document, error) {
+	docs := make(map[int64]*Document, len(ids))
+	if len(ids) == 0 {
+		return docs, nil
+	}
+
 	ctx := context.Background()
 
-	res, err := m.client.Query(ctx, DocumentsCollection, []string{}, "id > 0", []string{"id", "title", "content", "source_url", "doc_type", "metadata", "created_at"}, client.WithLimit(int64(limit)), client.WithOffset(int64(offset)))
+	idStrs := make([]string, len(ids))
+	for i, id := range ids {
+		idStrs[i] = strconv.FormatInt(id, 10)
+	}
+	expr := fmt.Sprintf("id in [%s]", strings.Join(idStrs, ","))
+
+	res, err := m.client.Query(ctx, DocumentsCollection, partitionList(tenantID), expr, []string{"id", "title", "content", "source_url", "doc_type", "metadata", "created_at"})
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list documents: %w", err)
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to batch-get documents", err)
 	}
 
 	count := res.GetColumn("id").Len()
-	docs := make([]Document, count)
+	for i := 0; i < count; i++ {
+		id := mustGetInt64(res, "id", i)
+		docs[id] = &Document{
+			ID:        id,
+			Title:     mustGetString(res, "title", i),
+			Content:   mustGetString(res, "content", i),
+			SourceURL: mustGetString(res, "source_url", i),
+			DocType:   mustGetString(res, "doc_type", i),
+			Metadata:  mustGetString(res, "metadata", i),
+			CreatedAt: mustGetInt64(res, "created_at", i),
+		}
+	}
+
+	return docs, nil
+}
+
+// ListDocuments returns up to limit documents with id > cursor from
+// tenantID's partition, ordered by id, satisfying the Store interface. It
+// over-fetches by one row to detect whether another page follows without a
+// second query, and caches the approximate total behind countCache instead
+// of counting on every call.
+func (m *MilvusClient) ListDocuments(cursor int64, limit int, filter ListFilter, tenantID string) ([]Document, int64, int64, error) {
+	ctx := context.Background()
+
+	expr, err := buildListExpr(cursor, filter)
+	if err != nil {
+		return nil, 0, 0, err
+	}
 
+	res, err := m.client.Query(
+		ctx, DocumentsCollection, partitionList(tenantID), expr,
+		[]string{"id", "title", "content", "source_url", "doc_type", "metadata", "created_at"},
+		client.WithLimit(int64(limit+1)),
+	)
+	if err != nil {
+		return nil, 0, 0, errs.Wrap(errs.CodeVectorStore, "failed to list documents", err)
+	}
+
+	count := res.GetColumn("id").Len()
+	docs := make([]Document, count)
 	for i := 0; i < count; i++ {
 		docs[i] = Document{
 			ID:        mustGetInt64(res, "id", i),
@@ -409,36 +876,215 @@ func (m *MilvusClient) ListDocuments(limit, offset int) ([]Document, int64, erro
 			CreatedAt: mustGetInt64(res, "created_at", i),
 		}
 	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
 
-	// Total count is hard to get efficiently in Milvus without a separate counter or Count() query which might be slow.
-	// For now returning count of current page or just -1 if unknown.
-	// Let's try to get total count.
-	countRes, err := m.client.Query(ctx, DocumentsCollection, []string{}, "id > 0", []string{"count(*)"})
-	var total int64
-	if err == nil && countRes.GetColumn("count(*)").Len() > 0 {
-		total = countRes.GetColumn("count(*)").(*entity.ColumnInt64).Data()[0]
+	var nextCursor int64
+	if len(docs) > limit {
+		nextCursor = docs[limit-1].ID
+		docs = docs[:limit]
 	}
 
-	return docs, total, nil
+	total, err := m.listCounts.get(tenantID+"|"+filter.cacheKey(), func() (int64, error) {
+		countExpr, err := buildListExpr(0, filter)
+		if err != nil {
+			return 0, err
+		}
+		countRes, err := m.client.Query(ctx, DocumentsCollection, partitionList(tenantID), countExpr, []string{"count(*)"})
+		if err != nil {
+			return 0, err
+		}
+		if countRes.GetColumn("count(*)").Len() == 0 {
+			return 0, nil
+		}
+		return countRes.GetColumn("count(*)").(*entity.ColumnInt64).Data()[0], nil
+	})
+	if err != nil {
+		return nil, 0, 0, errs.Wrap(errs.CodeVectorStore, "failed to count documents", err)
+	}
+
+	return docs, nextCursor, total, nil
+}
+
+// UpsertDocument idempotently re-ingests doc and chunks: it looks up an
+// existing document by ExternalID (if set) or SourceURL, then diffs chunks
+// against what's already stored by fingerprint (see chunkFingerprint)
+// instead of deleting everything and reinserting, so search never sees the
+// document's chunks go missing mid-reindex the way DeleteDocument+
+// CreateDocument does. Returns the document's ID, new or existing.
+func (m *MilvusClient) UpsertDocument(doc *Document, chunks []Chunk, tenantID string) (int64, error) {
+	ctx := context.Background()
+
+	docID, err := m.findDocumentForUpsert(ctx, doc, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	if docID == 0 {
+		docID, err = m.CreateDocument(doc, tenantID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for i := range chunks {
+		chunks[i].DocumentID = docID
+		if chunks[i].Fingerprint == "" {
+			chunks[i].Fingerprint = chunkFingerprint(docID, chunks[i].ChunkIndex, chunks[i].ChunkText)
+		}
+	}
+
+	existing, err := m.client.Query(ctx, CollectionName, partitionList(tenantID), fmt.Sprintf("document_id == %d", docID), []string{"id", "fingerprint"})
+	if err != nil {
+		return 0, errs.Wrap(errs.CodeVectorStore, "failed to query existing chunks", err)
+	}
+
+	existingIDs := make(map[string]int64, existing.GetColumn("id").Len())
+	for i := 0; i < existing.GetColumn("id").Len(); i++ {
+		existingIDs[mustGetString(existing, "fingerprint", i)] = mustGetInt64(existing, "id", i)
+	}
+
+	wanted := make(map[string]bool, len(chunks))
+	var toInsert []Chunk
+	for _, c := range chunks {
+		wanted[c.Fingerprint] = true
+		if _, ok := existingIDs[c.Fingerprint]; !ok {
+			toInsert = append(toInsert, c)
+		}
+	}
+
+	var staleFingerprints []string
+	var staleIDs []int64
+	for fp, id := range existingIDs {
+		if !wanted[fp] {
+			staleFingerprints = append(staleFingerprints, fp)
+			staleIDs = append(staleIDs, id)
+		}
+	}
+
+	if len(toInsert) > 0 {
+		if err := m.AddChunks(toInsert, tenantID); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(staleFingerprints) > 0 {
+		quoted := make([]string, len(staleFingerprints))
+		for i, fp := range staleFingerprints {
+			quoted[i] = fmt.Sprintf("%q", fp)
+		}
+		expr := fmt.Sprintf("fingerprint in [%s]", strings.Join(quoted, ","))
+		if err := m.client.Delete(ctx, CollectionName, tenantID, expr); err != nil {
+			return 0, errs.Wrap(errs.CodeVectorStore, "failed to delete stale chunks", err)
+		}
+		for _, id := range staleIDs {
+			m.bm25.remove(id)
+		}
+	}
+
+	return docID, nil
 }
 
-// DeleteDocument deletes a document and its chunks
-func (m *MilvusClient) DeleteDocument(id int64) error {
+// findDocumentForUpsert looks up an existing document for UpsertDocument: by
+// ExternalID if doc sets one, else by SourceURL. Returns 0 (not an error) if
+// neither is set or nothing matches, meaning UpsertDocument should create a
+// new document.
+func (m *MilvusClient) findDocumentForUpsert(ctx context.Context, doc *Document, tenantID string) (int64, error) {
+	// Milvus has no parameterized query primitive like Postgres's `?` args
+	// (see PGVectorStore.findDocumentForUpsert), so doc.ExternalID/SourceURL
+	// - caller-controlled via POST /documents/upsert - get interpolated
+	// straight into the filter expression string. %q alone isn't a safe
+	// escape here: reject any value containing a quote or backslash rather
+	// than risk it breaking out of the string literal and matching or
+	// overwriting an unrelated document.
+	var expr string
+	switch {
+	case doc.ExternalID != "":
+		if strings.ContainsAny(doc.ExternalID, `"\`) {
+			return 0, errs.New(errs.CodeValidation, "external_id must not contain quote or backslash characters")
+		}
+		expr = fmt.Sprintf("external_id == %q", doc.ExternalID)
+	case doc.SourceURL != "":
+		if strings.ContainsAny(doc.SourceURL, `"\`) {
+			return 0, errs.New(errs.CodeValidation, "source_url must not contain quote or backslash characters")
+		}
+		expr = fmt.Sprintf("source_url == %q", doc.SourceURL)
+	default:
+		return 0, nil
+	}
+
+	res, err := m.client.Query(ctx, DocumentsCollection, partitionList(tenantID), expr, []string{"id"})
+	if err != nil {
+		return 0, errs.Wrap(errs.CodeVectorStore, "failed to look up document for upsert", err)
+	}
+	if res.GetColumn("id").Len() == 0 {
+		return 0, nil
+	}
+	return mustGetInt64(res, "id", 0), nil
+}
+
+// DeleteDocument deletes a document and its chunks from tenantID's partition.
+func (m *MilvusClient) DeleteDocument(id int64, tenantID string) error {
 	ctx := context.Background()
 
 	// Delete document
-	if err := m.client.Delete(ctx, DocumentsCollection, "", fmt.Sprintf("id == %d", id)); err != nil {
-		return fmt.Errorf("failed to delete document: %w", err)
+	if err := m.client.Delete(ctx, DocumentsCollection, tenantID, fmt.Sprintf("id == %d", id)); err != nil {
+		return errs.Wrap(errs.CodeVectorStore, "failed to delete document", err)
 	}
 
 	// Delete chunks
-	if err := m.client.Delete(ctx, CollectionName, "", fmt.Sprintf("document_id == %d", id)); err != nil {
-		return fmt.Errorf("failed to delete chunks: %w", err)
+	if err := m.client.Delete(ctx, CollectionName, tenantID, fmt.Sprintf("document_id == %d", id)); err != nil {
+		return errs.Wrap(errs.CodeVectorStore, "failed to delete chunks", err)
 	}
 
+	m.bm25.removeDocument(id)
+
 	return nil
 }
 
+// rrfFuse combines two ranked SearchResult lists with Reciprocal Rank
+// Fusion: score(d) = Σ 1/(rrfK + rank_i(d)) across whichever lists contain
+// d, using (document ID, chunk text) as the identity of a candidate. The
+// result is truncated to limit, ordered by descending fused score.
+func rrfFuse(dense, sparse []SearchResult, rrfK, limit int) []SearchResult {
+	if rrfK <= 0 {
+		rrfK = 60
+	}
+
+	type candidate struct {
+		result SearchResult
+		score  float64
+	}
+
+	byKey := make(map[string]*candidate, len(dense)+len(sparse))
+
+	add := func(list []SearchResult) {
+		for rank, res := range list {
+			key := fmt.Sprintf("%d:%s", res.DocumentID, res.ChunkText)
+			c, ok := byKey[key]
+			if !ok {
+				c = &candidate{result: res}
+				byKey[key] = c
+			}
+			c.score += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+	add(dense)
+	add(sparse)
+
+	fused := make([]SearchResult, 0, len(byKey))
+	for _, c := range byKey {
+		c.result.Score = float32(c.score)
+		fused = append(fused, c.result)
+	}
+
+	sort.Slice(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+
+	if limit > 0 && len(fused) > limit {
+		fused = fused[:limit]
+	}
+
+	return fused
+}
+
 // Helper functions for extracting data from columns
 func mustGetString(rs client.ResultSet, fieldName string, row int) string {
 	col := rs.GetColumn(fieldName)
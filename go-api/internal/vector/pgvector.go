@@ -0,0 +1,522 @@
+package vector
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kavishankarks/itp-rag-processor/go-api/internal/errs"
+	"gorm.io/gorm"
+)
+
+// PGVectorStore implements Store on top of the `document_chunks` /
+// `documents` tables created by database.Initialize, using the `vector`
+// extension's `<=>` cosine distance operator against the HNSW index.
+type PGVectorStore struct {
+	db         *gorm.DB
+	listCounts *countCache
+}
+
+// NewPGVectorStore wraps an already-initialized *gorm.DB (see
+// database.Initialize) as a vector.Store.
+func NewPGVectorStore(db *gorm.DB) *PGVectorStore {
+	return &PGVectorStore{db: db, listCounts: newCountCache()}
+}
+
+// EnsureCollections is a no-op: the tables, extensions, and HNSW index are
+// created by database.Initialize.
+func (p *PGVectorStore) EnsureCollections() error {
+	return nil
+}
+
+// Close is a no-op; the underlying *gorm.DB is owned by the caller.
+func (p *PGVectorStore) Close() {}
+
+// Flush is a no-op: Postgres writes are visible to other queries as soon as
+// the transaction that made them commits, so there's no separate flush step.
+func (p *PGVectorStore) Flush() error {
+	return nil
+}
+
+// EnsurePartition is a no-op: pgvector has no partition primitive, and every
+// tenant already shares the same tables.
+func (p *PGVectorStore) EnsurePartition(tenantID string) error {
+	return nil
+}
+
+// DropPartition is a no-op for the same reason as EnsurePartition; tenant
+// offboarding on this backend means deleting that tenant's documents, which
+// this Store interface has no tenant-scoped bulk-delete for yet.
+func (p *PGVectorStore) DropPartition(tenantID string) error {
+	return nil
+}
+
+// Upsert inserts chunk embeddings into document_chunks. tenantID is ignored;
+// see the Store doc comment.
+func (p *PGVectorStore) Upsert(chunks []Chunk, tenantID string) error {
+	for _, chunk := range chunks {
+		fingerprint := chunk.Fingerprint
+		if fingerprint == "" {
+			fingerprint = chunkFingerprint(chunk.DocumentID, chunk.ChunkIndex, chunk.ChunkText)
+		}
+		err := p.db.Exec(
+			`INSERT INTO document_chunks (document_id, chunk_index, chunk_text, metadata, doc_type, embedding, fingerprint)
+			 VALUES (?, ?, ?, ?, ?, ?::vector, ?)`,
+			chunk.DocumentID, chunk.ChunkIndex, chunk.ChunkText, chunk.Metadata, chunk.DocType, encodeVector(chunk.Embedding), fingerprint,
+		).Error
+		if err != nil {
+			return errs.Wrap(errs.CodeVectorStore, "failed to upsert chunk", err)
+		}
+	}
+	return nil
+}
+
+// Search performs cosine-distance ANN search against the HNSW index,
+// narrowed by any SearchOptions given. WithFilter's raw expression is
+// Milvus-specific boolean syntax and has no Postgres equivalent here, so it
+// is ignored by this backend; WithDocumentIDs/WithDocType/WithTimeRange
+// translate directly to a SQL WHERE clause.
+func (p *PGVectorStore) Search(queryVector []float32, limit int, minScore float64, opts ...SearchOption) ([]SearchResult, error) {
+	literal := encodeVector(queryVector)
+	where, args := pgFilterClause(opts...)
+
+	rows, err := p.db.Raw(
+		fmt.Sprintf(
+			`SELECT document_id, chunk_index, chunk_text, metadata, 1 - (embedding <=> ?::vector) AS score
+			 FROM document_chunks
+			 %s
+			 ORDER BY embedding <=> ?::vector
+			 LIMIT ?`,
+			where,
+		),
+		append(append([]interface{}{literal}, args...), literal, limit)...,
+	).Rows()
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to search", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var metadata sql.NullString
+		var score float64
+		if err := rows.Scan(&r.DocumentID, &r.ChunkIndex, &r.ChunkText, &metadata, &score); err != nil {
+			return nil, errs.Wrap(errs.CodeVectorStore, "failed to scan search row", err)
+		}
+		r.Metadata = metadata.String
+		if score < minScore {
+			continue
+		}
+		r.Score = float32(score)
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// SearchDocuments performs cosine-distance ANN search against the
+// documents.embedding HNSW index, narrowed by any SearchOptions given.
+// WithFilter is ignored for the same reason as in Search; WithDocumentIDs/
+// WithDocType/WithTimeRange translate directly to a SQL WHERE clause.
+func (p *PGVectorStore) SearchDocuments(queryVector []float32, limit int, opts ...SearchOption) ([]DocumentSearchResult, error) {
+	literal := encodeVector(queryVector)
+	where, args := pgFilterClause(opts...)
+
+	rows, err := p.db.Raw(
+		fmt.Sprintf(
+			`SELECT id, title, content, source_url, doc_type, metadata, created_at, 1 - (embedding <=> ?::vector) AS score
+			 FROM documents
+			 %s
+			 ORDER BY embedding <=> ?::vector
+			 LIMIT ?`,
+			where,
+		),
+		append(append([]interface{}{literal}, args...), literal, limit)...,
+	).Rows()
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to search documents", err)
+	}
+	defer rows.Close()
+
+	var results []DocumentSearchResult
+	for rows.Next() {
+		var r DocumentSearchResult
+		var createdAt time.Time
+		var metadata sql.NullString
+		var score float64
+		if err := rows.Scan(&r.ID, &r.Title, &r.Content, &r.SourceURL, &r.DocType, &metadata, &createdAt, &score); err != nil {
+			return nil, errs.Wrap(errs.CodeVectorStore, "failed to scan document search row", err)
+		}
+		r.Metadata = metadata.String
+		r.CreatedAt = createdAt.Unix()
+		r.Score = float32(score)
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// FullTextSearch runs a lexical search over document_chunks.chunk_text using
+// Postgres' built-in text search (websearch_to_tsquery + ts_rank), ranked
+// best first.
+func (p *PGVectorStore) FullTextSearch(query string, limit int) ([]SearchResult, error) {
+	rows, err := p.db.Raw(
+		`SELECT document_id, chunk_index, chunk_text, metadata,
+		        ts_rank(to_tsvector('english', chunk_text), websearch_to_tsquery('english', ?)) AS score
+		 FROM document_chunks
+		 WHERE to_tsvector('english', chunk_text) @@ websearch_to_tsquery('english', ?)
+		 ORDER BY score DESC
+		 LIMIT ?`,
+		query, query, limit,
+	).Rows()
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to run full-text search", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var metadata sql.NullString
+		var score float64
+		if err := rows.Scan(&r.DocumentID, &r.ChunkIndex, &r.ChunkText, &metadata, &score); err != nil {
+			return nil, errs.Wrap(errs.CodeVectorStore, "failed to scan full-text search row", err)
+		}
+		r.Metadata = metadata.String
+		r.Score = float32(score)
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// Delete removes a document and its chunks. tenantID is ignored; see the
+// Store doc comment.
+func (p *PGVectorStore) Delete(documentID int64, tenantID string) error {
+	if err := p.db.Exec(`DELETE FROM documents WHERE id = ?`, documentID).Error; err != nil {
+		return errs.Wrap(errs.CodeVectorStore, "failed to delete document", err)
+	}
+	if err := p.db.Exec(`DELETE FROM document_chunks WHERE document_id = ?`, documentID).Error; err != nil {
+		return errs.Wrap(errs.CodeVectorStore, "failed to delete chunks", err)
+	}
+	return nil
+}
+
+// CreateDocument creates a new document row and returns its ID. tenantID is
+// ignored; see the Store doc comment.
+func (p *PGVectorStore) CreateDocument(doc *Document, tenantID string) (int64, error) {
+	// A caller that hasn't computed a document-level embedding yet (e.g. over
+	// title+summary) still has to satisfy the column's fixed dimension; fall
+	// back to the zero vector so it's excluded from SearchDocuments results
+	// by similarity alone, matching MilvusClient.CreateDocument.
+	embedding := doc.Embedding
+	if len(embedding) == 0 {
+		embedding = make([]float32, Dim)
+	}
+
+	var id int64
+	row := p.db.Raw(
+		`INSERT INTO documents (title, content, source_url, doc_type, metadata, embedding, external_id, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?::vector, ?, NOW(), NOW())
+		 RETURNING id`,
+		doc.Title, doc.Content, doc.SourceURL, doc.DocType, doc.Metadata, encodeVector(embedding), doc.ExternalID,
+	).Row()
+
+	if err := row.Scan(&id); err != nil {
+		if strings.Contains(err.Error(), "idx_documents_title_unique") {
+			return 0, errs.Newf(errs.CodeDuplicate, "document with title '%s' already exists", doc.Title)
+		}
+		return 0, errs.Wrap(errs.CodeVectorStore, "failed to insert document", err)
+	}
+
+	return id, nil
+}
+
+// UpsertDocument idempotently re-ingests doc and chunks, satisfying the Store
+// interface: it finds an existing document by ExternalID (if set) or
+// SourceURL, diffs chunks against what's already stored by fingerprint (see
+// chunkFingerprint), inserts only the new ones, and deletes only the ones no
+// longer produced in a single DELETE ... WHERE fingerprint = ANY(...). tenantID
+// is ignored; see the Store doc comment.
+func (p *PGVectorStore) UpsertDocument(doc *Document, chunks []Chunk, tenantID string) (int64, error) {
+	docID, err := p.findDocumentForUpsert(doc)
+	if err != nil {
+		return 0, err
+	}
+	if docID == 0 {
+		docID, err = p.CreateDocument(doc, tenantID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for i := range chunks {
+		chunks[i].DocumentID = docID
+		if chunks[i].Fingerprint == "" {
+			chunks[i].Fingerprint = chunkFingerprint(docID, chunks[i].ChunkIndex, chunks[i].ChunkText)
+		}
+	}
+
+	rows, err := p.db.Raw(
+		`SELECT fingerprint FROM document_chunks WHERE document_id = ? AND fingerprint IS NOT NULL`,
+		docID,
+	).Rows()
+	if err != nil {
+		return 0, errs.Wrap(errs.CodeVectorStore, "failed to query existing chunks", err)
+	}
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var fp string
+		if err := rows.Scan(&fp); err != nil {
+			rows.Close()
+			return 0, errs.Wrap(errs.CodeVectorStore, "failed to scan existing chunk fingerprint", err)
+		}
+		existing[fp] = true
+	}
+	rows.Close()
+
+	wanted := make(map[string]bool, len(chunks))
+	var toInsert []Chunk
+	for _, c := range chunks {
+		wanted[c.Fingerprint] = true
+		if !existing[c.Fingerprint] {
+			toInsert = append(toInsert, c)
+		}
+	}
+
+	var staleFingerprints []string
+	for fp := range existing {
+		if !wanted[fp] {
+			staleFingerprints = append(staleFingerprints, fp)
+		}
+	}
+
+	if len(toInsert) > 0 {
+		if err := p.Upsert(toInsert, tenantID); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(staleFingerprints) > 0 {
+		if err := p.db.Exec(
+			`DELETE FROM document_chunks WHERE document_id = ? AND fingerprint = ANY(?)`,
+			docID, staleFingerprints,
+		).Error; err != nil {
+			return 0, errs.Wrap(errs.CodeVectorStore, "failed to delete stale chunks", err)
+		}
+	}
+
+	return docID, nil
+}
+
+// findDocumentForUpsert looks up an existing document for UpsertDocument: by
+// ExternalID if doc sets one, else by SourceURL. Returns 0 (not an error) if
+// neither is set or nothing matches, meaning UpsertDocument should create a
+// new document.
+func (p *PGVectorStore) findDocumentForUpsert(doc *Document) (int64, error) {
+	var column, value string
+	switch {
+	case doc.ExternalID != "":
+		column, value = "external_id", doc.ExternalID
+	case doc.SourceURL != "":
+		column, value = "source_url", doc.SourceURL
+	default:
+		return 0, nil
+	}
+
+	var id int64
+	row := p.db.Raw(fmt.Sprintf(`SELECT id FROM documents WHERE %s = ?`, column), value).Row()
+	if err := row.Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, errs.Wrap(errs.CodeVectorStore, "failed to look up document for upsert", err)
+	}
+	return id, nil
+}
+
+// GetDocument retrieves a document by ID. tenantID is ignored; see the Store
+// doc comment.
+func (p *PGVectorStore) GetDocument(id int64, tenantID string) (*Document, error) {
+	var doc Document
+	var createdAt time.Time
+	var metadata sql.NullString
+
+	row := p.db.Raw(
+		`SELECT id, title, content, source_url, doc_type, metadata, created_at
+		 FROM documents WHERE id = ?`,
+		id,
+	).Row()
+
+	if err := row.Scan(&doc.ID, &doc.Title, &doc.Content, &doc.SourceURL, &doc.DocType, &metadata, &createdAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.Newf(errs.CodeNotFound, "document %d not found", id)
+		}
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to get document", err)
+	}
+
+	doc.Metadata = metadata.String
+	doc.CreatedAt = createdAt.Unix()
+
+	return &doc, nil
+}
+
+// GetDocumentsByIDs batch-fetches every document in ids with a single
+// `WHERE id IN (...)` query, instead of one GetDocument round-trip per id.
+// tenantID is ignored; see the Store doc comment. Missing ids are simply
+// absent from the returned map rather than an error.
+func (p *PGVectorStore) GetDocumentsByIDs(ids []int64, tenantID string) (map[int64]*Document, error) {
+	docs := make(map[int64]*Document, len(ids))
+	if len(ids) == 0 {
+		return docs, nil
+	}
+
+	rows, err := p.db.Raw(
+		`SELECT id, title, content, source_url, doc_type, metadata, created_at
+		 FROM documents WHERE id IN ?`,
+		ids,
+	).Rows()
+	if err != nil {
+		return nil, errs.Wrap(errs.CodeVectorStore, "failed to batch-get documents", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var doc Document
+		var createdAt time.Time
+		var metadata sql.NullString
+		if err := rows.Scan(&doc.ID, &doc.Title, &doc.Content, &doc.SourceURL, &doc.DocType, &metadata, &createdAt); err != nil {
+			return nil, errs.Wrap(errs.CodeVectorStore, "failed to scan document row", err)
+		}
+		doc.Metadata = metadata.String
+		doc.CreatedAt = createdAt.Unix()
+		docs[doc.ID] = &doc
+	}
+
+	return docs, nil
+}
+
+// ListDocuments returns up to limit documents with id > cursor, ordered by
+// id, satisfying the Store interface. tenantID is ignored; see the Store doc
+// comment. It over-fetches by one row to detect whether another page
+// follows, and caches the approximate total behind countCache instead of
+// counting on every call.
+func (p *PGVectorStore) ListDocuments(cursor int64, limit int, filter ListFilter, tenantID string) ([]Document, int64, int64, error) {
+	where, args := pgListFilterClause(cursor, filter)
+
+	rows, err := p.db.Raw(
+		fmt.Sprintf(
+			`SELECT id, title, content, source_url, doc_type, metadata, created_at
+			 FROM documents %s ORDER BY id LIMIT ?`,
+			where,
+		),
+		append(args, limit+1)...,
+	).Rows()
+	if err != nil {
+		return nil, 0, 0, errs.Wrap(errs.CodeVectorStore, "failed to list documents", err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var doc Document
+		var createdAt time.Time
+		var metadata sql.NullString
+
+		if err := rows.Scan(&doc.ID, &doc.Title, &doc.Content, &doc.SourceURL, &doc.DocType, &metadata, &createdAt); err != nil {
+			return nil, 0, 0, errs.Wrap(errs.CodeVectorStore, "failed to scan document row", err)
+		}
+		doc.Metadata = metadata.String
+		doc.CreatedAt = createdAt.Unix()
+		docs = append(docs, doc)
+	}
+
+	var nextCursor int64
+	if len(docs) > limit {
+		nextCursor = docs[limit-1].ID
+		docs = docs[:limit]
+	}
+
+	total, err := p.listCounts.get(filter.cacheKey(), func() (int64, error) {
+		where, args := pgListFilterClause(0, filter)
+		var total int64
+		if err := p.db.Raw(fmt.Sprintf(`SELECT count(*) FROM documents %s`, where), args...).Row().Scan(&total); err != nil {
+			return 0, err
+		}
+		return total, nil
+	})
+	if err != nil {
+		return nil, 0, 0, errs.Wrap(errs.CodeVectorStore, "failed to count documents", err)
+	}
+
+	return docs, nextCursor, total, nil
+}
+
+// pgListFilterClause renders cursor and f as a "WHERE ..." clause with
+// positional args for ListDocuments, matching buildListExpr's semantics.
+func pgListFilterClause(cursor int64, f ListFilter) (string, []interface{}) {
+	clauses := []string{"id > ?"}
+	args := []interface{}{cursor}
+
+	if f.DocType != "" {
+		clauses = append(clauses, "doc_type = ?")
+		args = append(args, f.DocType)
+	}
+	if f.CreatedAfter != 0 {
+		clauses = append(clauses, "created_at > to_timestamp(?)")
+		args = append(args, f.CreatedAfter)
+	}
+	if f.TitlePrefix != "" {
+		clauses = append(clauses, "title LIKE ?")
+		args = append(args, f.TitlePrefix+"%")
+	}
+
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// pgFilterClause renders the subset of SearchOptions that translate to SQL
+// (WithDocumentIDs, WithDocType, WithTimeRange) as a "WHERE ..." clause with
+// positional args, or ("", nil) if opts set nothing translatable.
+func pgFilterClause(opts ...SearchOption) (string, []interface{}) {
+	var f searchFilter
+	for _, opt := range opts {
+		opt(&f)
+	}
+
+	var clauses []string
+	var args []interface{}
+
+	if len(f.documentIDs) > 0 {
+		clauses = append(clauses, "document_id IN ?")
+		args = append(args, f.documentIDs)
+	}
+	if f.docType != "" {
+		clauses = append(clauses, "doc_type = ?")
+		args = append(args, f.docType)
+	}
+	if f.from != 0 || f.to != 0 {
+		// created_at is a timestamptz column; WithTimeRange's bounds are unix
+		// seconds, matching the units Milvus's int64 created_at field stores.
+		clauses = append(clauses, "created_at BETWEEN to_timestamp(?) AND to_timestamp(?)")
+		args = append(args, f.from, f.to)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// encodeVector renders a float32 slice as a pgvector input literal, e.g.
+// "[0.1,0.2,0.3]".
+func encodeVector(v []float32) string {
+	parts := make([]string, len(v))
+	for i, f := range v {
+		parts[i] = strconv.FormatFloat(float64(f), 'f', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
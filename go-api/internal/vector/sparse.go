@@ -0,0 +1,66 @@
+package vector
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// SparseEmbedding is a sparse vector representation carried alongside a
+// chunk's dense embedding: parallel arrays of token positions and their
+// weights (e.g. a SPLADE or BM25-derived encoding), used by HybridSearch's
+// sparse ANN subquery.
+type SparseEmbedding struct {
+	Positions []uint32
+	Values    []float32
+}
+
+// Len returns the number of non-zero entries in the sparse vector.
+func (s SparseEmbedding) Len() int {
+	return len(s.Positions)
+}
+
+// Get returns the (position, value) pair at idx, or ok=false if idx is out
+// of range.
+func (s SparseEmbedding) Get(idx int) (uint32, float32, bool) {
+	if idx < 0 || idx >= len(s.Positions) {
+		return 0, 0, false
+	}
+	return s.Positions[idx], s.Values[idx], true
+}
+
+// Dot computes the inner product between s and other, treating both as
+// sparse vectors over the same (implicit, e.g. SPLADE vocabulary) position
+// space. Positions are assumed sorted ascending, as every producer in this
+// package (Milvus's wire format, the sparseembed service) emits them. Used
+// to score a handful of candidates against a query's sparse encoding
+// without needing a full ANN index.
+func (s SparseEmbedding) Dot(other SparseEmbedding) float32 {
+	var score float32
+	i, j := 0, 0
+	for i < len(s.Positions) && j < len(other.Positions) {
+		switch {
+		case s.Positions[i] == other.Positions[j]:
+			score += s.Values[i] * other.Values[j]
+			i++
+			j++
+		case s.Positions[i] < other.Positions[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return score
+}
+
+// Serialize encodes the sparse vector as Milvus expects a
+// FieldTypeSparseFloatVector row on the wire: each (position, value) pair as
+// a little-endian uint32 position followed by the IEEE-754 bits of the
+// float32 value, packed back to back at offset idx*8.
+func (s SparseEmbedding) Serialize() []byte {
+	buf := make([]byte, s.Len()*8)
+	for i, pos := range s.Positions {
+		binary.LittleEndian.PutUint32(buf[i*8:], pos)
+		binary.LittleEndian.PutUint32(buf[i*8+4:], math.Float32bits(s.Values[i]))
+	}
+	return buf
+}
@@ -0,0 +1,116 @@
+package vector
+
+// Store abstracts the vector backend used for document and chunk storage so
+// the handlers, pipeline orchestrator, and generation flow can run against
+// Milvus or Postgres/pgvector interchangeably.
+//
+// Most methods take a tenantID that scopes the call to one tenant's data: on
+// Milvus this is the partition name (see EnsurePartition); on Postgres it's
+// ignored, since pgvector has no partition primitive and every tenant shares
+// the same tables. Pass "" to operate on the default/untenanted partition.
+type Store interface {
+	// EnsureCollections provisions whatever schema/indexes the backend needs
+	// (Milvus collections, Postgres tables/indexes) and is safe to call
+	// repeatedly.
+	EnsureCollections() error
+
+	// EnsurePartition provisions the given tenant's partition on both the
+	// chunks and documents collections if it doesn't already exist. A no-op
+	// on backends without a partition primitive.
+	EnsurePartition(tenantID string) error
+
+	// DropPartition removes a tenant's partition (and everything stored in
+	// it) in O(1), for tenant offboarding. A no-op on backends without a
+	// partition primitive.
+	DropPartition(tenantID string) error
+
+	// Upsert writes chunk embeddings to the backend, into tenantID's
+	// partition.
+	Upsert(chunks []Chunk, tenantID string) error
+
+	// UpsertDocument idempotently re-ingests doc and chunks: it finds an
+	// existing document by ExternalID (if set) or SourceURL, diffs chunks
+	// against what's already stored by fingerprint (see chunkFingerprint),
+	// inserts only the new ones, and deletes only the ones no longer
+	// produced. Unlike Delete followed by CreateDocument/Upsert, the
+	// document's unchanged chunks stay searchable throughout. Returns the
+	// document's ID, new or existing.
+	UpsertDocument(doc *Document, chunks []Chunk, tenantID string) (int64, error)
+
+	// Flush forces the backend to make recently written chunks visible to
+	// search before returning. Milvus buffers inserts until a segment is
+	// sealed; Postgres/pgvector has no such delay, so implementations there
+	// are no-ops.
+	Flush() error
+
+	// Search runs a dense similarity search and returns the closest chunks,
+	// narrowed by any SearchOptions given (WithFilter, WithDocumentIDs,
+	// WithDocType, WithTimeRange, WithTenants). WithTenants accepts multiple
+	// partition names so admins can run cross-tenant queries; with no
+	// WithTenants option the search runs across every partition.
+	Search(queryVector []float32, limit int, minScore float64, opts ...SearchOption) ([]SearchResult, error)
+
+	// SearchDocuments runs a dense similarity search over document-level
+	// embeddings (see Document.Embedding) instead of chunks, returning the
+	// closest documents best-score-first. Intended for coarse-to-fine RAG:
+	// narrow to the top documents here, then Search/HybridSearch within just
+	// their chunks via WithDocumentIDs.
+	SearchDocuments(queryVector []float32, limit int, opts ...SearchOption) ([]DocumentSearchResult, error)
+
+	// FullTextSearch runs a lexical (BM25/tsvector-style) query over chunk
+	// text and returns the closest matches, ranked best first. Both backends
+	// support this: Postgres uses tsvector/ts_rank, Milvus uses an in-memory
+	// BM25 inverted index (see bm25.go) kept in sync with AddChunks/
+	// DeleteDocument since it has no native lexical index of its own. Unlike
+	// the other methods here, this is not tenant-scoped: the BM25 index and
+	// Postgres tsvector both span every tenant's chunks.
+	FullTextSearch(query string, limit int) ([]SearchResult, error)
+
+	// Delete removes a document and all of its chunks from tenantID's
+	// partition.
+	Delete(documentID int64, tenantID string) error
+
+	CreateDocument(doc *Document, tenantID string) (int64, error)
+	GetDocument(id int64, tenantID string) (*Document, error)
+
+	// GetDocumentsByIDs batch-fetches every document in ids from tenantID's
+	// partition in a single round-trip, keyed by ID, instead of one
+	// GetDocument call per id. An id with no matching document is simply
+	// absent from the returned map.
+	GetDocumentsByIDs(ids []int64, tenantID string) (map[int64]*Document, error)
+
+	// ListDocuments returns up to limit documents with id > cursor, ordered
+	// by id, along with nextCursor to pass on the next call (0 once there
+	// are no more documents matching filter). This keyset approach avoids
+	// Milvus's offset+limit re-scan and its ~16k offset cap. total is an
+	// approximate count of documents matching filter, cached for
+	// countCacheTTL since computing it exactly on every call is expensive on
+	// both backends.
+	ListDocuments(cursor int64, limit int, filter ListFilter, tenantID string) (docs []Document, nextCursor int64, total int64, err error)
+
+	Close()
+}
+
+var _ Store = (*MilvusClient)(nil)
+
+// Upsert stores chunk embeddings, satisfying the Store interface.
+func (m *MilvusClient) Upsert(chunks []Chunk, tenantID string) error {
+	return m.AddChunks(chunks, tenantID)
+}
+
+// Delete removes a document and its chunks, satisfying the Store interface.
+func (m *MilvusClient) Delete(documentID int64, tenantID string) error {
+	return m.DeleteDocument(documentID, tenantID)
+}
+
+// Flush seals the chunks collection so recently inserted chunks become
+// searchable, satisfying the Store interface.
+func (m *MilvusClient) Flush() error {
+	return m.FlushChunks()
+}
+
+// FullTextSearch runs Okapi BM25 scoring over the in-memory inverted index
+// built from this collection's chunks, satisfying the Store interface.
+func (m *MilvusClient) FullTextSearch(query string, limit int) ([]SearchResult, error) {
+	return m.bm25.search(query, limit)
+}